@@ -0,0 +1,82 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// ErrAttachmentTooLarge is returned by saveBlob when the uploaded data
+// exceeds the configured maximum size.
+var ErrAttachmentTooLarge = errors.New("файл превышает допустимый размер")
+
+// Attachment is a file uploaded to a task, stored content-addressed by its
+// SHA-256 hash so identical files across tasks share a single blob.
+type Attachment struct {
+	ID          string `json:"id"`
+	Filename    string `json:"filename"`
+	Size        int64  `json:"size"`
+	ContentType string `json:"contentType"`
+	SHA256      string `json:"sha256"`
+}
+
+// saveBlob streams r into dir, rejecting anything past maxSize, and names
+// the result after its SHA-256 hash so re-uploading identical content
+// reuses the existing file instead of duplicating it on disk.
+func saveBlob(dir string, r io.Reader, maxSize int64) (sha256Hex string, size int64, err error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", 0, err
+	}
+
+	tmp, err := os.CreateTemp(dir, "upload-*")
+	if err != nil {
+		return "", 0, err
+	}
+	tmpPath := tmp.Name()
+
+	hasher := sha256.New()
+	written, copyErr := io.Copy(io.MultiWriter(tmp, hasher), io.LimitReader(r, maxSize+1))
+	closeErr := tmp.Close()
+	if copyErr != nil {
+		os.Remove(tmpPath)
+		return "", 0, copyErr
+	}
+	if closeErr != nil {
+		os.Remove(tmpPath)
+		return "", 0, closeErr
+	}
+	if written > maxSize {
+		os.Remove(tmpPath)
+		return "", 0, ErrAttachmentTooLarge
+	}
+
+	sum := hex.EncodeToString(hasher.Sum(nil))
+	finalPath := filepath.Join(dir, sum)
+	if _, err := os.Stat(finalPath); err == nil {
+		// Блоб с таким содержимым уже есть — используем его, временный
+		// файл больше не нужен.
+		os.Remove(tmpPath)
+		return sum, written, nil
+	} else if !os.IsNotExist(err) {
+		os.Remove(tmpPath)
+		return "", 0, err
+	}
+
+	if err := os.Rename(tmpPath, finalPath); err != nil {
+		os.Remove(tmpPath)
+		return "", 0, err
+	}
+	return sum, written, nil
+}
+
+func findAttachment(task Task, id string) (Attachment, bool) {
+	for _, a := range task.Attachments {
+		if a.ID == id {
+			return a, true
+		}
+	}
+	return Attachment{}, false
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// attachmentBlobDir and maxAttachmentSize are configured from main via
+// flags/env; see newAttachmentConfig.
+var attachmentBlobDir = "attachments"
+var maxAttachmentSize int64 = 10 << 20 // 10 MiB
+
+// handleUploadAttachment serves POST /api/tasks/{id}/attachments.
+func handleUploadAttachment(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Неверный ID задачи", http.StatusBadRequest)
+		return
+	}
+
+	ownerID := userFromContext(r.Context()).ID
+	if _, err := checkTaskOwnership(id, ownerID); err != nil {
+		writeTaskOwnershipError(w, err)
+		return
+	}
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		http.Error(w, "Ожидается multipart/form-data", http.StatusBadRequest)
+		return
+	}
+
+	var part *multipart.Part
+	for {
+		p, err := reader.NextPart()
+		if err == io.EOF {
+			http.Error(w, "Не найдено поле file", http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			log.Printf("Ошибка чтения multipart-запроса: %v", err)
+			http.Error(w, "Неверное тело запроса", http.StatusBadRequest)
+			return
+		}
+		if p.FormName() == "file" {
+			part = p
+			break
+		}
+		p.Close()
+	}
+	defer part.Close()
+
+	sum, size, err := saveBlob(attachmentBlobDir, part, maxAttachmentSize)
+	if err == ErrAttachmentTooLarge {
+		http.Error(w, "Файл превышает допустимый размер", http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
+		log.Printf("Ошибка сохранения вложения: %v", err)
+		http.Error(w, "Не удалось сохранить вложение", http.StatusInternalServerError)
+		return
+	}
+
+	attachmentID, err := randomToken()
+	if err != nil {
+		log.Printf("Ошибка генерации ID вложения: %v", err)
+		http.Error(w, "Не удалось сохранить вложение", http.StatusInternalServerError)
+		return
+	}
+
+	attachment := Attachment{
+		ID:          attachmentID,
+		Filename:    part.FileName(),
+		Size:        size,
+		ContentType: part.Header.Get("Content-Type"),
+		SHA256:      sum,
+	}
+
+	task, err := taskStore.Update(id, func(t *Task) {
+		t.Attachments = append(t.Attachments, attachment)
+	})
+	if err != nil {
+		log.Printf("Ошибка обновления задачи: %v", err)
+		http.Error(w, "Не удалось сохранить вложение", http.StatusInternalServerError)
+		return
+	}
+
+	eventHub.Publish(Event{Type: EventUpdated, Task: task})
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(attachment)
+}
+
+// handleDownloadAttachment serves GET /api/tasks/{id}/attachments/{aid}.
+func handleDownloadAttachment(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Неверный ID задачи", http.StatusBadRequest)
+		return
+	}
+	aid := chi.URLParam(r, "aid")
+
+	ownerID := userFromContext(r.Context()).ID
+	task, err := checkTaskOwnership(id, ownerID)
+	if err != nil {
+		writeTaskOwnershipError(w, err)
+		return
+	}
+
+	attachment, ok := findAttachment(task, aid)
+	if !ok {
+		http.Error(w, "Вложение не найдено", http.StatusNotFound)
+		return
+	}
+
+	f, err := os.Open(filepath.Join(attachmentBlobDir, attachment.SHA256))
+	if err != nil {
+		log.Printf("Ошибка открытия вложения: %v", err)
+		http.Error(w, "Не удалось прочитать вложение", http.StatusInternalServerError)
+		return
+	}
+	defer f.Close()
+
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", attachment.Filename))
+	io.Copy(w, f)
+}
+
+// handleDeleteAttachment serves DELETE /api/tasks/{id}/attachments/{aid}.
+// The underlying blob is left on disk, since other tasks may reference the
+// same content-addressed file.
+func handleDeleteAttachment(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Неверный ID задачи", http.StatusBadRequest)
+		return
+	}
+	aid := chi.URLParam(r, "aid")
+
+	ownerID := userFromContext(r.Context()).ID
+	if _, err := checkTaskOwnership(id, ownerID); err != nil {
+		writeTaskOwnershipError(w, err)
+		return
+	}
+
+	found := false
+	task, err := taskStore.Update(id, func(t *Task) {
+		kept := t.Attachments[:0]
+		for _, a := range t.Attachments {
+			if a.ID == aid {
+				found = true
+				continue
+			}
+			kept = append(kept, a)
+		}
+		t.Attachments = kept
+	})
+	if err != nil {
+		log.Printf("Ошибка обновления задачи: %v", err)
+		http.Error(w, "Не удалось удалить вложение", http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, "Вложение не найдено", http.StatusNotFound)
+		return
+	}
+
+	eventHub.Publish(Event{Type: EventUpdated, Task: task})
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const sessionCookieName = "session_id"
+const csrfHeaderName = "X-CSRF-Token"
+
+type contextKey int
+
+const userContextKey contextKey = 0
+
+var userStore UserStore
+var sessionStore = NewSessionStore()
+
+type credentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+func handleSignup(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var creds credentials
+	if err := json.NewDecoder(r.Body).Decode(&creds); err != nil {
+		http.Error(w, "Неверное тело запроса", http.StatusBadRequest)
+		return
+	}
+	if creds.Username == "" || creds.Password == "" {
+		http.Error(w, "Укажите имя пользователя и пароль", http.StatusBadRequest)
+		return
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(creds.Password), bcrypt.DefaultCost)
+	if err != nil {
+		log.Printf("Ошибка хеширования пароля: %v", err)
+		http.Error(w, "Не удалось создать учетную запись", http.StatusInternalServerError)
+		return
+	}
+
+	user, err := userStore.Create(creds.Username, hash)
+	if err == ErrUsernameTaken {
+		http.Error(w, "Имя пользователя уже занято", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Printf("Ошибка создания пользователя: %v", err)
+		http.Error(w, "Не удалось создать учетную запись", http.StatusInternalServerError)
+		return
+	}
+
+	if err := startSession(w, user); err != nil {
+		log.Printf("Ошибка создания сессии: %v", err)
+		http.Error(w, "Не удалось создать сессию", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(user)
+}
+
+func handleLogin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var creds credentials
+	if err := json.NewDecoder(r.Body).Decode(&creds); err != nil {
+		http.Error(w, "Неверное тело запроса", http.StatusBadRequest)
+		return
+	}
+
+	user, err := userStore.GetByUsername(creds.Username)
+	if err == ErrUserNotFound {
+		http.Error(w, "Неверное имя пользователя или пароль", http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		log.Printf("Ошибка получения пользователя: %v", err)
+		http.Error(w, "Не удалось выполнить вход", http.StatusInternalServerError)
+		return
+	}
+
+	if err := bcrypt.CompareHashAndPassword(user.PasswordHash, []byte(creds.Password)); err != nil {
+		http.Error(w, "Неверное имя пользователя или пароль", http.StatusUnauthorized)
+		return
+	}
+
+	if err := startSession(w, user); err != nil {
+		log.Printf("Ошибка создания сессии: %v", err)
+		http.Error(w, "Не удалось создать сессию", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(user)
+}
+
+func handleLogout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if cookie, err := r.Cookie(sessionCookieName); err == nil {
+		sessionStore.Delete(cookie.Value)
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+	})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// startSession creates a session for user and sets the session cookie on
+// the response. The CSRF token is returned to the client via a response
+// header rather than the (HttpOnly) cookie, since JS needs to read it back
+// for mutating requests.
+func startSession(w http.ResponseWriter, user User) error {
+	session, err := sessionStore.Create(user.ID)
+	if err != nil {
+		return err
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    session.ID,
+		Path:     "/",
+		HttpOnly: true,
+		Expires:  session.ExpiresAt,
+	})
+	w.Header().Set(csrfHeaderName, session.CSRFToken)
+	return nil
+}
+
+// requireAuth wraps next so it only runs for requests carrying a valid
+// session cookie, and additionally requires a matching CSRF header on
+// mutating methods. The authenticated user is attached to the request
+// context and can be read with userFromContext.
+func requireAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie(sessionCookieName)
+		if err != nil {
+			http.Error(w, "Требуется вход в систему", http.StatusUnauthorized)
+			return
+		}
+
+		session, ok := sessionStore.Get(cookie.Value)
+		if !ok {
+			http.Error(w, "Сессия недействительна или истекла", http.StatusUnauthorized)
+			return
+		}
+
+		if isMutatingMethod(r.Method) && r.Header.Get(csrfHeaderName) != session.CSRFToken {
+			http.Error(w, "Неверный CSRF токен", http.StatusForbidden)
+			return
+		}
+
+		user, err := userStore.GetByID(session.UserID)
+		if err != nil {
+			http.Error(w, "Требуется вход в систему", http.StatusUnauthorized)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), userContextKey, user)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// userFromContext returns the authenticated user attached by requireAuth.
+func userFromContext(ctx context.Context) User {
+	return ctx.Value(userContextKey).(User)
+}
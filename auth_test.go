@@ -0,0 +1,130 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func withTestUserStore(t *testing.T) User {
+	t.Helper()
+	prevUsers, prevSessions := userStore, sessionStore
+	userStore = NewMemoryUserStore()
+	sessionStore = NewSessionStore()
+	t.Cleanup(func() {
+		userStore = prevUsers
+		sessionStore = prevSessions
+	})
+
+	user, err := userStore.Create("alice", []byte("hash"))
+	if err != nil {
+		t.Fatalf("создание пользователя: %v", err)
+	}
+	return user
+}
+
+func authenticatedHandler() (http.Handler, *bool) {
+	called := false
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusOK)
+	})
+	return requireAuth(next), &called
+}
+
+func TestRequireAuthRejectsMissingCookie(t *testing.T) {
+	withTestUserStore(t)
+	handler, called := authenticatedHandler()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/tasks", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("ожидался 401, получен %d", rec.Code)
+	}
+	if *called {
+		t.Fatal("next не должен вызываться без cookie")
+	}
+}
+
+func TestRequireAuthRejectsUnknownSession(t *testing.T) {
+	withTestUserStore(t)
+	handler, called := authenticatedHandler()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/tasks", nil)
+	req.AddCookie(&http.Cookie{Name: sessionCookieName, Value: "несуществующий-токен"})
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("ожидался 401, получен %d", rec.Code)
+	}
+	if *called {
+		t.Fatal("next не должен вызываться при неизвестной сессии")
+	}
+}
+
+func TestRequireAuthRejectsMutatingRequestWithoutCSRFHeader(t *testing.T) {
+	user := withTestUserStore(t)
+	session, err := sessionStore.Create(user.ID)
+	if err != nil {
+		t.Fatalf("создание сессии: %v", err)
+	}
+	handler, called := authenticatedHandler()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/tasks", nil)
+	req.AddCookie(&http.Cookie{Name: sessionCookieName, Value: session.ID})
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("ожидался 403, получен %d", rec.Code)
+	}
+	if *called {
+		t.Fatal("next не должен вызываться без верного CSRF токена")
+	}
+}
+
+func TestRequireAuthAllowsMutatingRequestWithCSRFHeader(t *testing.T) {
+	user := withTestUserStore(t)
+	session, err := sessionStore.Create(user.ID)
+	if err != nil {
+		t.Fatalf("создание сессии: %v", err)
+	}
+	handler, called := authenticatedHandler()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/tasks", nil)
+	req.AddCookie(&http.Cookie{Name: sessionCookieName, Value: session.ID})
+	req.Header.Set(csrfHeaderName, session.CSRFToken)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("ожидался 200, получен %d", rec.Code)
+	}
+	if !*called {
+		t.Fatal("next должен вызываться с верным CSRF токеном")
+	}
+}
+
+func TestRequireAuthAllowsNonMutatingRequestWithoutCSRFHeader(t *testing.T) {
+	user := withTestUserStore(t)
+	session, err := sessionStore.Create(user.ID)
+	if err != nil {
+		t.Fatalf("создание сессии: %v", err)
+	}
+	handler, called := authenticatedHandler()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/tasks", nil)
+	req.AddCookie(&http.Cookie{Name: sessionCookieName, Value: session.ID})
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("ожидался 200, получен %d", rec.Code)
+	}
+	if !*called {
+		t.Fatal("next должен вызываться для GET без CSRF токена")
+	}
+}
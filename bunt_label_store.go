@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/tidwall/buntdb"
+)
+
+const (
+	labelKeyPrefix  = "label:"
+	nextLabelIDKey  = "meta:nextlabelid"
+	labelOwnerIndex = "labelOwnerId"
+)
+
+// BuntLabelStore persists labels to a BuntDB file, with a secondary index
+// on OwnerID for listing a user's labels.
+type BuntLabelStore struct {
+	db *buntdb.DB
+}
+
+// NewBuntLabelStore opens (creating if necessary) a BuntDB-backed
+// LabelStore at path.
+func NewBuntLabelStore(path string) (*BuntLabelStore, error) {
+	db, err := buntdb.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("открытие хранилища меток %q: %w", path, err)
+	}
+	if err := db.CreateIndex(labelOwnerIndex, labelKeyPrefix+"*", buntdb.IndexJSON("ownerId")); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("создание индекса ownerId: %w", err)
+	}
+	return &BuntLabelStore{db: db}, nil
+}
+
+// Close releases the underlying BuntDB file.
+func (s *BuntLabelStore) Close() error {
+	return s.db.Close()
+}
+
+func labelKey(id int) string {
+	return fmt.Sprintf("%s%d", labelKeyPrefix, id)
+}
+
+func (s *BuntLabelStore) Get(id int) (Label, error) {
+	var label Label
+	err := s.db.View(func(tx *buntdb.Tx) error {
+		val, err := tx.Get(labelKey(id))
+		if err != nil {
+			if err == buntdb.ErrNotFound {
+				return ErrLabelNotFound
+			}
+			return err
+		}
+		return json.Unmarshal([]byte(val), &label)
+	})
+	if err != nil {
+		return Label{}, err
+	}
+	return label, nil
+}
+
+func (s *BuntLabelStore) ListByOwner(ownerID int) ([]Label, error) {
+	labels := make([]Label, 0)
+	err := s.db.View(func(tx *buntdb.Tx) error {
+		pivot := fmt.Sprintf(`{"ownerId":%d}`, ownerID)
+		return tx.AscendEqual(labelOwnerIndex, pivot, func(key, val string) bool {
+			var label Label
+			if err := json.Unmarshal([]byte(val), &label); err != nil {
+				return true
+			}
+			labels = append(labels, label)
+			return true
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return labels, nil
+}
+
+func (s *BuntLabelStore) Create(label Label) (Label, error) {
+	err := s.db.Update(func(tx *buntdb.Tx) error {
+		id, err := nextLabelID(tx)
+		if err != nil {
+			return err
+		}
+		label.ID = id
+
+		data, err := json.Marshal(label)
+		if err != nil {
+			return err
+		}
+		_, _, err = tx.Set(labelKey(label.ID), string(data), nil)
+		return err
+	})
+	if err != nil {
+		return Label{}, err
+	}
+	return label, nil
+}
+
+func nextLabelID(tx *buntdb.Tx) (int, error) {
+	id := 1
+	val, err := tx.Get(nextLabelIDKey)
+	switch err {
+	case nil:
+		id, err = strconv.Atoi(val)
+		if err != nil {
+			return 0, fmt.Errorf("чтение счетчика ID метки: %w", err)
+		}
+	case buntdb.ErrNotFound:
+		// первая метка в хранилище
+	default:
+		return 0, err
+	}
+
+	if _, _, err := tx.Set(nextLabelIDKey, strconv.Itoa(id+1), nil); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/tidwall/buntdb"
+)
+
+const (
+	projectKeyPrefix  = "project:"
+	nextProjectIDKey  = "meta:nextprojectid"
+	projectOwnerIndex = "projectOwnerId"
+)
+
+// BuntProjectStore persists projects to a BuntDB file, with a secondary
+// index on OwnerID for listing a user's projects.
+type BuntProjectStore struct {
+	db *buntdb.DB
+}
+
+// NewBuntProjectStore opens (creating if necessary) a BuntDB-backed
+// ProjectStore at path.
+func NewBuntProjectStore(path string) (*BuntProjectStore, error) {
+	db, err := buntdb.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("открытие хранилища проектов %q: %w", path, err)
+	}
+	if err := db.CreateIndex(projectOwnerIndex, projectKeyPrefix+"*", buntdb.IndexJSON("ownerId")); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("создание индекса ownerId: %w", err)
+	}
+	return &BuntProjectStore{db: db}, nil
+}
+
+// Close releases the underlying BuntDB file.
+func (s *BuntProjectStore) Close() error {
+	return s.db.Close()
+}
+
+func projectKey(id int) string {
+	return fmt.Sprintf("%s%d", projectKeyPrefix, id)
+}
+
+func (s *BuntProjectStore) Get(id int) (Project, error) {
+	var project Project
+	err := s.db.View(func(tx *buntdb.Tx) error {
+		val, err := tx.Get(projectKey(id))
+		if err != nil {
+			if err == buntdb.ErrNotFound {
+				return ErrProjectNotFound
+			}
+			return err
+		}
+		return json.Unmarshal([]byte(val), &project)
+	})
+	if err != nil {
+		return Project{}, err
+	}
+	return project, nil
+}
+
+func (s *BuntProjectStore) ListByOwner(ownerID int) ([]Project, error) {
+	projects := make([]Project, 0)
+	err := s.db.View(func(tx *buntdb.Tx) error {
+		pivot := fmt.Sprintf(`{"ownerId":%d}`, ownerID)
+		return tx.AscendEqual(projectOwnerIndex, pivot, func(key, val string) bool {
+			var project Project
+			if err := json.Unmarshal([]byte(val), &project); err != nil {
+				return true
+			}
+			projects = append(projects, project)
+			return true
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
+
+func (s *BuntProjectStore) Create(project Project) (Project, error) {
+	err := s.db.Update(func(tx *buntdb.Tx) error {
+		id, err := nextProjectID(tx)
+		if err != nil {
+			return err
+		}
+		project.ID = id
+
+		data, err := json.Marshal(project)
+		if err != nil {
+			return err
+		}
+		_, _, err = tx.Set(projectKey(project.ID), string(data), nil)
+		return err
+	})
+	if err != nil {
+		return Project{}, err
+	}
+	return project, nil
+}
+
+func nextProjectID(tx *buntdb.Tx) (int, error) {
+	id := 1
+	val, err := tx.Get(nextProjectIDKey)
+	switch err {
+	case nil:
+		id, err = strconv.Atoi(val)
+		if err != nil {
+			return 0, fmt.Errorf("чтение счетчика ID проекта: %w", err)
+		}
+	case buntdb.ErrNotFound:
+		// первый проект в хранилище
+	default:
+		return 0, err
+	}
+
+	if _, _, err := tx.Set(nextProjectIDKey, strconv.Itoa(id+1), nil); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/tidwall/buntdb"
+)
+
+const (
+	taskKeyPrefix       = "task:"
+	nextTaskIDKey       = "meta:nextid"
+	completedIndex      = "completed"
+	ownerIndex          = "ownerId"
+	parentIndex         = "parentId"
+	projectIndex        = "projectId"
+	ownerCompletedIndex = "ownerCompleted"
+)
+
+// BuntTaskStore persists tasks to a single BuntDB file, keyed by task ID,
+// with secondary indices on OwnerID, ParentID, ProjectID and Completed (plus
+// a composite OwnerID+Completed index, since that's the pair handleGetTasks
+// actually filters by) so common lookups don't require a full scan.
+type BuntTaskStore struct {
+	db *buntdb.DB
+}
+
+// NewBuntTaskStore opens (creating if necessary) a BuntDB-backed TaskStore
+// at path.
+func NewBuntTaskStore(path string) (*BuntTaskStore, error) {
+	db, err := buntdb.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("открытие хранилища %q: %w", path, err)
+	}
+	if err := db.CreateIndex(completedIndex, taskKeyPrefix+"*", buntdb.IndexJSON("completed")); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("создание индекса completed: %w", err)
+	}
+	if err := db.CreateIndex(ownerIndex, taskKeyPrefix+"*", buntdb.IndexJSON("ownerId")); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("создание индекса ownerId: %w", err)
+	}
+	if err := db.CreateIndex(parentIndex, taskKeyPrefix+"*", buntdb.IndexJSON("parentId")); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("создание индекса parentId: %w", err)
+	}
+	if err := db.CreateIndex(projectIndex, taskKeyPrefix+"*", buntdb.IndexJSON("projectId")); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("создание индекса projectId: %w", err)
+	}
+	if err := db.CreateIndex(ownerCompletedIndex, taskKeyPrefix+"*", buntdb.IndexJSON("ownerId"), buntdb.IndexJSON("completed")); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("создание индекса ownerId+completed: %w", err)
+	}
+	return &BuntTaskStore{db: db}, nil
+}
+
+// Close releases the underlying BuntDB file.
+func (s *BuntTaskStore) Close() error {
+	return s.db.Close()
+}
+
+func taskKey(id int) string {
+	return fmt.Sprintf("%s%d", taskKeyPrefix, id)
+}
+
+func (s *BuntTaskStore) Get(id int) (Task, error) {
+	var task Task
+	err := s.db.View(func(tx *buntdb.Tx) error {
+		val, err := tx.Get(taskKey(id))
+		if err != nil {
+			if err == buntdb.ErrNotFound {
+				return ErrTaskNotFound
+			}
+			return err
+		}
+		return json.Unmarshal([]byte(val), &task)
+	})
+	if err != nil {
+		return Task{}, err
+	}
+	return task, nil
+}
+
+func (s *BuntTaskStore) List(filter TaskFilter) ([]Task, error) {
+	tasks := make([]Task, 0)
+	iter := func(key, val string) bool {
+		var task Task
+		if err := json.Unmarshal([]byte(val), &task); err != nil {
+			return true
+		}
+		if matchesFilter(task, filter) {
+			tasks = append(tasks, task)
+		}
+		return true
+	}
+
+	err := s.db.View(func(tx *buntdb.Tx) error {
+		switch {
+		case filter.OwnerID != nil && filter.Completed != nil:
+			pivot := fmt.Sprintf(`{"ownerId":%d,"completed":%t}`, *filter.OwnerID, *filter.Completed)
+			return tx.AscendEqual(ownerCompletedIndex, pivot, iter)
+		case filter.OwnerID != nil:
+			pivot := fmt.Sprintf(`{"ownerId":%d}`, *filter.OwnerID)
+			return tx.AscendEqual(ownerIndex, pivot, iter)
+		case filter.ParentID != nil:
+			pivot := fmt.Sprintf(`{"parentId":%d}`, *filter.ParentID)
+			return tx.AscendEqual(parentIndex, pivot, iter)
+		case filter.ProjectID != nil:
+			pivot := fmt.Sprintf(`{"projectId":%d}`, *filter.ProjectID)
+			return tx.AscendEqual(projectIndex, pivot, iter)
+		case filter.Completed != nil:
+			pivot := fmt.Sprintf(`{"completed":%t}`, *filter.Completed)
+			return tx.AscendEqual(completedIndex, pivot, iter)
+		default:
+			return tx.Ascend("", iter)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+func (s *BuntTaskStore) Create(task Task) (Task, error) {
+	err := s.db.Update(func(tx *buntdb.Tx) error {
+		id, err := nextTaskID(tx)
+		if err != nil {
+			return err
+		}
+		task.ID = id
+
+		data, err := json.Marshal(task)
+		if err != nil {
+			return err
+		}
+		_, _, err = tx.Set(taskKey(task.ID), string(data), nil)
+		return err
+	})
+	if err != nil {
+		return Task{}, err
+	}
+	return task, nil
+}
+
+func (s *BuntTaskStore) Update(id int, fn func(*Task)) (Task, error) {
+	var task Task
+	err := s.db.Update(func(tx *buntdb.Tx) error {
+		val, err := tx.Get(taskKey(id))
+		if err != nil {
+			if err == buntdb.ErrNotFound {
+				return ErrTaskNotFound
+			}
+			return err
+		}
+		if err := json.Unmarshal([]byte(val), &task); err != nil {
+			return err
+		}
+
+		fn(&task)
+		task.ID = id
+
+		data, err := json.Marshal(task)
+		if err != nil {
+			return err
+		}
+		_, _, err = tx.Set(taskKey(id), string(data), nil)
+		return err
+	})
+	if err != nil {
+		return Task{}, err
+	}
+	return task, nil
+}
+
+func (s *BuntTaskStore) Delete(id int) error {
+	return s.db.Update(func(tx *buntdb.Tx) error {
+		_, err := tx.Delete(taskKey(id))
+		if err == buntdb.ErrNotFound {
+			return ErrTaskNotFound
+		}
+		return err
+	})
+}
+
+// nextTaskID reads and increments the persisted ID counter as part of tx,
+// so concurrent creates never hand out the same ID.
+func nextTaskID(tx *buntdb.Tx) (int, error) {
+	id := 1
+	val, err := tx.Get(nextTaskIDKey)
+	switch err {
+	case nil:
+		id, err = strconv.Atoi(val)
+		if err != nil {
+			return 0, fmt.Errorf("чтение счетчика ID: %w", err)
+		}
+	case buntdb.ErrNotFound:
+		// первая задача в хранилище
+	default:
+		return 0, err
+	}
+
+	if _, _, err := tx.Set(nextTaskIDKey, strconv.Itoa(id+1), nil); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/tidwall/buntdb"
+)
+
+const (
+	userKeyPrefix     = "user:"
+	usernameKeyPrefix = "username:"
+	nextUserIDKey     = "meta:nextuserid"
+)
+
+// userRecord is the on-disk representation of a User. User.PasswordHash is
+// tagged json:"-" so it round-trips to API clients safely; userRecord
+// re-includes it so it survives persistence.
+type userRecord struct {
+	ID           int    `json:"id"`
+	Username     string `json:"username"`
+	PasswordHash []byte `json:"passwordHash"`
+}
+
+// BuntUserStore persists user accounts to a BuntDB file, with a secondary
+// key mapping usernames to IDs for fast lookup on login.
+type BuntUserStore struct {
+	db *buntdb.DB
+}
+
+// NewBuntUserStore opens (creating if necessary) a BuntDB-backed UserStore
+// at path.
+func NewBuntUserStore(path string) (*BuntUserStore, error) {
+	db, err := buntdb.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("открытие хранилища пользователей %q: %w", path, err)
+	}
+	return &BuntUserStore{db: db}, nil
+}
+
+// Close releases the underlying BuntDB file.
+func (s *BuntUserStore) Close() error {
+	return s.db.Close()
+}
+
+func userKey(id int) string {
+	return fmt.Sprintf("%s%d", userKeyPrefix, id)
+}
+
+func usernameKey(username string) string {
+	return usernameKeyPrefix + username
+}
+
+func (s *BuntUserStore) GetByID(id int) (User, error) {
+	var rec userRecord
+	err := s.db.View(func(tx *buntdb.Tx) error {
+		val, err := tx.Get(userKey(id))
+		if err != nil {
+			if err == buntdb.ErrNotFound {
+				return ErrUserNotFound
+			}
+			return err
+		}
+		return json.Unmarshal([]byte(val), &rec)
+	})
+	if err != nil {
+		return User{}, err
+	}
+	return User{ID: rec.ID, Username: rec.Username, PasswordHash: rec.PasswordHash}, nil
+}
+
+func (s *BuntUserStore) GetByUsername(username string) (User, error) {
+	var rec userRecord
+	err := s.db.View(func(tx *buntdb.Tx) error {
+		idStr, err := tx.Get(usernameKey(username))
+		if err != nil {
+			if err == buntdb.ErrNotFound {
+				return ErrUserNotFound
+			}
+			return err
+		}
+		val, err := tx.Get(userKeyPrefix + idStr)
+		if err != nil {
+			if err == buntdb.ErrNotFound {
+				return ErrUserNotFound
+			}
+			return err
+		}
+		return json.Unmarshal([]byte(val), &rec)
+	})
+	if err != nil {
+		return User{}, err
+	}
+	return User{ID: rec.ID, Username: rec.Username, PasswordHash: rec.PasswordHash}, nil
+}
+
+func (s *BuntUserStore) Create(username string, passwordHash []byte) (User, error) {
+	var rec userRecord
+	err := s.db.Update(func(tx *buntdb.Tx) error {
+		if _, err := tx.Get(usernameKey(username)); err == nil {
+			return ErrUsernameTaken
+		} else if err != buntdb.ErrNotFound {
+			return err
+		}
+
+		id, err := nextUserID(tx)
+		if err != nil {
+			return err
+		}
+		rec = userRecord{ID: id, Username: username, PasswordHash: passwordHash}
+
+		data, err := json.Marshal(rec)
+		if err != nil {
+			return err
+		}
+		if _, _, err := tx.Set(userKey(id), string(data), nil); err != nil {
+			return err
+		}
+		_, _, err = tx.Set(usernameKey(username), strconv.Itoa(id), nil)
+		return err
+	})
+	if err != nil {
+		return User{}, err
+	}
+	return User{ID: rec.ID, Username: rec.Username, PasswordHash: rec.PasswordHash}, nil
+}
+
+func nextUserID(tx *buntdb.Tx) (int, error) {
+	id := 1
+	val, err := tx.Get(nextUserIDKey)
+	switch err {
+	case nil:
+		id, err = strconv.Atoi(val)
+		if err != nil {
+			return 0, fmt.Errorf("чтение счетчика ID пользователя: %w", err)
+		}
+	case buntdb.ErrNotFound:
+		// первый пользователь в хранилище
+	default:
+		return 0, err
+	}
+
+	if _, _, err := tx.Set(nextUserIDKey, strconv.Itoa(id+1), nil); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
@@ -0,0 +1,174 @@
+package main
+
+import "sync"
+
+// EventType identifies what happened to a task in an Event.
+type EventType string
+
+const (
+	EventCreated   EventType = "created"
+	EventUpdated   EventType = "updated"
+	EventCompleted EventType = "completed"
+	EventDeleted   EventType = "deleted"
+)
+
+// Event is a single task change, broadcast to clients subscribed to the
+// owning user's task stream.
+type Event struct {
+	Seq  int       `json:"seq"`
+	Type EventType `json:"type"`
+	Task Task      `json:"task"`
+}
+
+// hubHistoryLimit bounds how many past events the hub keeps around for
+// clients reconnecting with a `since` cursor.
+const hubHistoryLimit = 500
+
+// client is a single connected WebSocket subscriber. send is written to by
+// both the hub goroutine (publish, replay) and the connection's own replay
+// goroutine, so mu guards closing it: once closed is set, no goroutine may
+// send on send again.
+type client struct {
+	id      string
+	ownerID int
+	send    chan Event
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// deliver blocks until e is sent to c, or does nothing if c has already been
+// unregistered. Used for replaying buffered events, where dropping one isn't
+// acceptable.
+func (c *client) deliver(e Event) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return
+	}
+	c.send <- e
+}
+
+// tryDeliver sends e to c without blocking, dropping it if c's buffer is full
+// or c has already been unregistered.
+func (c *client) tryDeliver(e Event) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return
+	}
+	select {
+	case c.send <- e:
+	default:
+		// клиент не успевает читать — пропускаем событие,
+		// не блокируя хаб остальным подписчикам
+	}
+}
+
+// close marks c as unregistered and closes send. Safe to call once; c.mu
+// ensures it never races with deliver/tryDeliver.
+func (c *client) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return
+	}
+	c.closed = true
+	close(c.send)
+}
+
+type replayRequest struct {
+	ownerID int
+	since   int
+	resp    chan []Event
+}
+
+// Hub is a small in-process pub/sub broker: one goroutine owns the set of
+// connected clients and the recent event history, and everything else talks
+// to it over channels.
+type Hub struct {
+	register   chan *client
+	unregister chan *client
+	publish    chan Event
+	replay     chan replayRequest
+
+	clients map[string]*client
+	history []Event
+	nextSeq int
+}
+
+// NewHub creates a Hub and starts its broker goroutine.
+func NewHub() *Hub {
+	h := &Hub{
+		register:   make(chan *client),
+		unregister: make(chan *client),
+		publish:    make(chan Event),
+		replay:     make(chan replayRequest),
+		clients:    make(map[string]*client),
+		nextSeq:    1,
+	}
+	go h.run()
+	return h
+}
+
+func (h *Hub) run() {
+	for {
+		select {
+		case c := <-h.register:
+			h.clients[c.id] = c
+
+		case c := <-h.unregister:
+			if _, ok := h.clients[c.id]; ok {
+				delete(h.clients, c.id)
+				c.close()
+			}
+
+		case e := <-h.publish:
+			e.Seq = h.nextSeq
+			h.nextSeq++
+			h.history = append(h.history, e)
+			if len(h.history) > hubHistoryLimit {
+				h.history = h.history[len(h.history)-hubHistoryLimit:]
+			}
+			for _, c := range h.clients {
+				if c.ownerID != e.Task.OwnerID {
+					continue
+				}
+				c.tryDeliver(e)
+			}
+
+		case req := <-h.replay:
+			var missed []Event
+			for _, e := range h.history {
+				if e.Seq > req.since && e.Task.OwnerID == req.ownerID {
+					missed = append(missed, e)
+				}
+			}
+			req.resp <- missed
+		}
+	}
+}
+
+// Register subscribes c to future events.
+func (h *Hub) Register(c *client) {
+	h.register <- c
+}
+
+// Unregister removes c and closes its send channel.
+func (h *Hub) Unregister(c *client) {
+	h.unregister <- c
+}
+
+// Publish broadcasts e to every client owning the affected task.
+func (h *Hub) Publish(e Event) {
+	h.publish <- e
+}
+
+// Replay returns buffered events for ownerID with Seq greater than since.
+func (h *Hub) Replay(ownerID, since int) []Event {
+	resp := make(chan []Event)
+	h.replay <- replayRequest{ownerID: ownerID, since: since, resp: resp}
+	return <-resp
+}
+
+var eventHub = NewHub()
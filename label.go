@@ -0,0 +1,26 @@
+package main
+
+import "errors"
+
+// ErrLabelNotFound is returned by LabelStore implementations when no label
+// matches the requested ID.
+var ErrLabelNotFound = errors.New("метка не найдена")
+
+// Label is a named, colored tag that can be attached to tasks.
+type Label struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	Color   string `json:"color"`
+	OwnerID int    `json:"ownerId"`
+}
+
+// LabelStore persists labels. Implementations must be safe for concurrent
+// use.
+type LabelStore interface {
+	// Get returns the label with the given ID, or ErrLabelNotFound.
+	Get(id int) (Label, error)
+	// ListByOwner returns every label owned by ownerID.
+	ListByOwner(ownerID int) ([]Label, error)
+	// Create assigns an ID to label and persists it.
+	Create(label Label) (Label, error)
+}
@@ -2,34 +2,33 @@ package main
 
 import (
 	"encoding/json"
+	"flag"
 	"fmt"
-	"io"
 	"log"
 	"net/http"
+	"os"
 	"strconv"
-	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
 )
 
 type Task struct {
-	ID        int    `json:"id"`
-	Title     string `json:"title"`
-	Completed bool   `json:"completed"`
-}
-
-type TaskStore struct {
-	sync.RWMutex
-	tasks  map[int]Task
-	nextID int
-}
-
-func NewTaskStore() *TaskStore {
-	return &TaskStore{
-		tasks:  make(map[int]Task),
-		nextID: 1,
-	}
+	ID          int          `json:"id"`
+	Title       string       `json:"title"`
+	Completed   bool         `json:"completed"`
+	OwnerID     int          `json:"ownerId"`
+	Schedule    *string      `json:"schedule,omitempty"`
+	ParentID    *int         `json:"parentId,omitempty"`
+	CreatedAt   time.Time    `json:"createdAt"`
+	DueDate     *time.Time   `json:"dueDate,omitempty"`
+	Priority    int          `json:"priority"`
+	ProjectID   *int         `json:"projectId,omitempty"`
+	LabelIDs    []int        `json:"labelIds,omitempty"`
+	Attachments []Attachment `json:"attachments,omitempty"`
 }
 
-var taskStore = NewTaskStore()
+var taskStore TaskStore
 
 func handleRoot(w http.ResponseWriter, r *http.Request) {
 	if r.URL.Path != "/" {
@@ -40,53 +39,87 @@ func handleRoot(w http.ResponseWriter, r *http.Request) {
 }
 
 func handleCreateTask(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
-		return
-	}
-
 	var task Task
 	if err := json.NewDecoder(r.Body).Decode(&task); err != nil {
 		log.Printf("Ошибка декодирования JSON: %v", err)
 		http.Error(w, "Неверное тело запроса", http.StatusBadRequest)
 		return
 	}
+	task.OwnerID = userFromContext(r.Context()).ID
+	task.CreatedAt = time.Now()
+
+	if err := validateProjectID(task.OwnerID, task.ProjectID); err != nil {
+		if err == errProjectOwnership {
+			http.Error(w, "Проект не найден", http.StatusBadRequest)
+			return
+		}
+		log.Printf("Ошибка проверки проекта: %v", err)
+		http.Error(w, "Не удалось создать задачу", http.StatusInternalServerError)
+		return
+	}
+	if err := validateLabelIDs(task.OwnerID, task.LabelIDs); err != nil {
+		if err == errLabelOwnership {
+			http.Error(w, "Метка не найдена", http.StatusBadRequest)
+			return
+		}
+		log.Printf("Ошибка проверки меток: %v", err)
+		http.Error(w, "Не удалось создать задачу", http.StatusInternalServerError)
+		return
+	}
 
-	taskStore.Lock()
-	task.ID = taskStore.nextID
-	taskStore.tasks[task.ID] = task
-	taskStore.nextID++
-	taskStore.Unlock()
+	task, err := taskStore.Create(task)
+	if err != nil {
+		log.Printf("Ошибка создания задачи: %v", err)
+		http.Error(w, "Не удалось создать задачу", http.StatusInternalServerError)
+		return
+	}
+
+	eventHub.Publish(Event{Type: EventCreated, Task: task})
 
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(task)
 }
 
 func handleGetTasks(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+	ownerID := userFromContext(r.Context()).ID
+	filter := TaskFilter{OwnerID: &ownerID}
+	if raw := r.URL.Query().Get("completed"); raw != "" {
+		completed, err := strconv.ParseBool(raw)
+		if err != nil {
+			http.Error(w, "Неверное значение параметра completed", http.StatusBadRequest)
+			return
+		}
+		filter.Completed = &completed
+	}
+	if raw := r.URL.Query().Get("project"); raw != "" {
+		projectID, err := strconv.Atoi(raw)
+		if err != nil {
+			http.Error(w, "Неверное значение параметра project", http.StatusBadRequest)
+			return
+		}
+		filter.ProjectID = &projectID
+	}
+
+	opts, err := parseTaskListOptions(r.URL.Query())
+	if err != nil {
+		writeInvalidParamError(w, err)
 		return
 	}
 
-	taskStore.RLock()
-	tasks := make([]Task, 0, len(taskStore.tasks))
-	for _, task := range taskStore.tasks {
-		tasks = append(tasks, task)
+	tasks, err := taskStore.List(filter)
+	if err != nil {
+		log.Printf("Ошибка получения задач: %v", err)
+		http.Error(w, "Не удалось получить задачи", http.StatusInternalServerError)
+		return
 	}
-	taskStore.RUnlock()
+	tasks = applyTaskListOptions(tasks, opts)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(tasks)
 }
 
 func handleUpdateTask(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPut {
-		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
-		return
-	}
-
-	idStr := r.URL.Path[len("/api/tasks/"):]
-	id, err := strconv.Atoi(idStr)
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
 	if err != nil {
 		http.Error(w, "Неверный ID задачи", http.StatusBadRequest)
 		return
@@ -99,102 +132,403 @@ func handleUpdateTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	taskStore.Lock()
-	if existingTask, exists := taskStore.tasks[id]; exists {
-		updatedTask.ID = id
+	ownerID := userFromContext(r.Context()).ID
+	if _, err := checkTaskOwnership(id, ownerID); err != nil {
+		writeTaskOwnershipError(w, err)
+		return
+	}
+
+	if err := validateProjectID(ownerID, updatedTask.ProjectID); err != nil {
+		if err == errProjectOwnership {
+			http.Error(w, "Проект не найден", http.StatusBadRequest)
+			return
+		}
+		log.Printf("Ошибка проверки проекта: %v", err)
+		http.Error(w, "Не удалось обновить задачу", http.StatusInternalServerError)
+		return
+	}
+	if err := validateLabelIDs(ownerID, updatedTask.LabelIDs); err != nil {
+		if err == errLabelOwnership {
+			http.Error(w, "Метка не найдена", http.StatusBadRequest)
+			return
+		}
+		log.Printf("Ошибка проверки меток: %v", err)
+		http.Error(w, "Не удалось обновить задачу", http.StatusInternalServerError)
+		return
+	}
+
+	task, err := taskStore.Update(id, func(t *Task) {
 		if updatedTask.Title != "" {
-			existingTask.Title = updatedTask.Title
+			t.Title = updatedTask.Title
+		}
+		t.Completed = updatedTask.Completed
+		if updatedTask.DueDate != nil {
+			t.DueDate = updatedTask.DueDate
+		}
+		if updatedTask.Priority != 0 {
+			t.Priority = updatedTask.Priority
 		}
-		existingTask.Completed = updatedTask.Completed
-		taskStore.tasks[id] = existingTask
-		taskStore.Unlock()
-		json.NewEncoder(w).Encode(existingTask)
+		if updatedTask.ProjectID != nil {
+			t.ProjectID = updatedTask.ProjectID
+		}
+		if updatedTask.LabelIDs != nil {
+			t.LabelIDs = updatedTask.LabelIDs
+		}
+	})
+	if err == ErrTaskNotFound {
+		http.Error(w, "Задача не найдена", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Ошибка обновления задачи: %v", err)
+		http.Error(w, "Не удалось обновить задачу", http.StatusInternalServerError)
 		return
 	}
-	taskStore.Unlock()
 
-	http.Error(w, "Задача не найдена", http.StatusNotFound)
+	eventHub.Publish(Event{Type: EventUpdated, Task: task})
+
+	json.NewEncoder(w).Encode(task)
 }
 
 func handleDeleteTask(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodDelete {
-		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Неверный ID задачи", http.StatusBadRequest)
 		return
 	}
 
-	idStr := r.URL.Path[len("/api/tasks/"):]
-	id, err := strconv.Atoi(idStr)
+	existing, err := checkTaskOwnership(id, userFromContext(r.Context()).ID)
 	if err != nil {
-		http.Error(w, "Неверный ID задачи", http.StatusBadRequest)
+		writeTaskOwnershipError(w, err)
 		return
 	}
 
-	taskStore.Lock()
-	if _, exists := taskStore.tasks[id]; exists {
-		delete(taskStore.tasks, id)
-		taskStore.Unlock()
-		w.WriteHeader(http.StatusNoContent)
+	err = taskStore.Delete(id)
+	if err == ErrTaskNotFound {
+		http.Error(w, "Задача не найдена", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Ошибка удаления задачи: %v", err)
+		http.Error(w, "Не удалось удалить задачу", http.StatusInternalServerError)
 		return
 	}
-	taskStore.Unlock()
 
-	http.Error(w, "Задача не найдена", http.StatusNotFound)
+	eventHub.Publish(Event{Type: EventDeleted, Task: existing})
+
+	w.WriteHeader(http.StatusNoContent)
 }
 
 func handleMarkTaskAsDone(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPut {
-		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Неверный ID задачи", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := checkTaskOwnership(id, userFromContext(r.Context()).ID); err != nil {
+		writeTaskOwnershipError(w, err)
+		return
+	}
+
+	task, err := taskStore.Update(id, func(t *Task) {
+		t.Completed = true
+	})
+	if err == ErrTaskNotFound {
+		http.Error(w, "Задача не найдена", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Ошибка обновления задачи: %v", err)
+		http.Error(w, "Не удалось обновить задачу", http.StatusInternalServerError)
+		return
+	}
+
+	eventHub.Publish(Event{Type: EventCompleted, Task: task})
+
+	json.NewEncoder(w).Encode(task)
+}
+
+type scheduleRequest struct {
+	Schedule string `json:"schedule"`
+}
+
+// handleSetTaskSchedule attaches or updates the cron schedule that turns a
+// task into a recurring template.
+func handleSetTaskSchedule(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Неверный ID задачи", http.StatusBadRequest)
+		return
+	}
+
+	var req scheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Ошибка декодирования JSON: %v", err)
+		http.Error(w, "Неверное тело запроса", http.StatusBadRequest)
+		return
+	}
+	if req.Schedule == "" {
+		http.Error(w, "Укажите расписание", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := checkTaskOwnership(id, userFromContext(r.Context()).ID); err != nil {
+		writeTaskOwnershipError(w, err)
+		return
+	}
+
+	task, err := taskStore.Update(id, func(t *Task) {
+		t.Schedule = &req.Schedule
+	})
+	if err != nil {
+		log.Printf("Ошибка обновления задачи: %v", err)
+		http.Error(w, "Не удалось обновить задачу", http.StatusInternalServerError)
 		return
 	}
 
-	idStr := r.URL.Path[len("/api/tasks/") : len(r.URL.Path)-len("/done")]
-	id, err := strconv.Atoi(idStr)
+	if err := scheduler.Register(task); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	json.NewEncoder(w).Encode(task)
+}
+
+// handleDeleteTaskSchedule detaches a task's schedule and stops it from
+// generating further recurring instances.
+func handleDeleteTaskSchedule(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
 	if err != nil {
 		http.Error(w, "Неверный ID задачи", http.StatusBadRequest)
 		return
 	}
 
-	taskStore.Lock()
-	if task, exists := taskStore.tasks[id]; exists {
-		task.Completed = true
-		taskStore.tasks[id] = task
-		taskStore.Unlock()
-		json.NewEncoder(w).Encode(task)
+	if _, err := checkTaskOwnership(id, userFromContext(r.Context()).ID); err != nil {
+		writeTaskOwnershipError(w, err)
 		return
 	}
-	taskStore.Unlock()
 
-	http.Error(w, "Задача не найдена", http.StatusNotFound)
+	scheduler.Unregister(id)
+
+	task, err := taskStore.Update(id, func(t *Task) {
+		t.Schedule = nil
+	})
+	if err != nil {
+		log.Printf("Ошибка обновления задачи: %v", err)
+		http.Error(w, "Не удалось обновить задачу", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(task)
 }
 
-func main() {
-	http.HandleFunc("/", handleRoot)
-	http.HandleFunc("/api/tasks", func(w http.ResponseWriter, r *http.Request) {
-		switch r.Method {
-		case http.MethodGet:
-			handleGetTasks(w, r)
-		case http.MethodPost:
-			handleCreateTask(w, r)
-		default:
-			http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+// checkTaskOwnership returns the task with the given ID if it exists and
+// belongs to ownerID, and ErrTaskNotFound otherwise — so handlers never
+// leak the existence of another user's task.
+func checkTaskOwnership(id, ownerID int) (Task, error) {
+	task, err := taskStore.Get(id)
+	if err != nil {
+		return Task{}, err
+	}
+	if task.OwnerID != ownerID {
+		return Task{}, ErrTaskNotFound
+	}
+	return task, nil
+}
+
+func writeTaskOwnershipError(w http.ResponseWriter, err error) {
+	if err == ErrTaskNotFound {
+		http.Error(w, "Задача не найдена", http.StatusNotFound)
+		return
+	}
+	log.Printf("Ошибка проверки владельца задачи: %v", err)
+	http.Error(w, "Не удалось выполнить операцию", http.StatusInternalServerError)
+}
+
+// getEnvDefault returns the value of the environment variable key, or
+// fallback if it is unset.
+func getEnvDefault(key, fallback string) string {
+	if val, ok := os.LookupEnv(key); ok {
+		return val
+	}
+	return fallback
+}
+
+// envInt64Default parses the environment variable key as an int64, or
+// returns fallback if it is unset or invalid.
+func envInt64Default(key string, fallback int64) int64 {
+	val, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+	parsed, err := strconv.ParseInt(val, 10, 64)
+	if err != nil {
+		return fallback
+	}
+	return parsed
+}
+
+func newTaskStore(backend, path string) (TaskStore, func(), error) {
+	switch backend {
+	case "memory":
+		return NewMemoryTaskStore(), func() {}, nil
+	case "bunt":
+		store, err := NewBuntTaskStore(path)
+		if err != nil {
+			return nil, nil, err
 		}
-	})
+		return store, func() { store.Close() }, nil
+	default:
+		return nil, nil, fmt.Errorf("неизвестный backend хранилища: %q (ожидается memory или bunt)", backend)
+	}
+}
+
+func newUserStore(backend, path string) (UserStore, func(), error) {
+	switch backend {
+	case "memory":
+		return NewMemoryUserStore(), func() {}, nil
+	case "bunt":
+		store, err := NewBuntUserStore(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		return store, func() { store.Close() }, nil
+	default:
+		return nil, nil, fmt.Errorf("неизвестный backend хранилища: %q (ожидается memory или bunt)", backend)
+	}
+}
+
+func newProjectStore(backend, path string) (ProjectStore, func(), error) {
+	switch backend {
+	case "memory":
+		return NewMemoryProjectStore(), func() {}, nil
+	case "bunt":
+		store, err := NewBuntProjectStore(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		return store, func() { store.Close() }, nil
+	default:
+		return nil, nil, fmt.Errorf("неизвестный backend хранилища: %q (ожидается memory или bunt)", backend)
+	}
+}
+
+func newLabelStore(backend, path string) (LabelStore, func(), error) {
+	switch backend {
+	case "memory":
+		return NewMemoryLabelStore(), func() {}, nil
+	case "bunt":
+		store, err := NewBuntLabelStore(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		return store, func() { store.Close() }, nil
+	default:
+		return nil, nil, fmt.Errorf("неизвестный backend хранилища: %q (ожидается memory или bunt)", backend)
+	}
+}
+
+func main() {
+	backend := flag.String("store", getEnvDefault("TODO_STORE_BACKEND", "bunt"), "backend хранилища задач: memory или bunt")
+	storePath := flag.String("store-path", getEnvDefault("TODO_STORE_PATH", "tasks.db"), "путь к файлу хранилища задач (для backend=bunt)")
+	userStorePath := flag.String("user-store-path", getEnvDefault("TODO_USER_STORE_PATH", "users.db"), "путь к файлу хранилища пользователей (для backend=bunt)")
+	projectStorePath := flag.String("project-store-path", getEnvDefault("TODO_PROJECT_STORE_PATH", "projects.db"), "путь к файлу хранилища проектов (для backend=bunt)")
+	labelStorePath := flag.String("label-store-path", getEnvDefault("TODO_LABEL_STORE_PATH", "labels.db"), "путь к файлу хранилища меток (для backend=bunt)")
+	attachmentDir := flag.String("attachment-dir", getEnvDefault("TODO_ATTACHMENT_DIR", "attachments"), "каталог для хранения вложений")
+	maxAttachmentMB := flag.Int64("max-attachment-mb", envInt64Default("TODO_MAX_ATTACHMENT_MB", 10), "максимальный размер вложения в мегабайтах")
+	flag.Parse()
+
+	attachmentBlobDir = *attachmentDir
+	maxAttachmentSize = *maxAttachmentMB << 20
+
+	store, closeStore, err := newTaskStore(*backend, *storePath)
+	if err != nil {
+		log.Fatalf("Ошибка инициализации хранилища задач: %v", err)
+	}
+	defer closeStore()
+	taskStore = store
+
+	users, closeUsers, err := newUserStore(*backend, *userStorePath)
+	if err != nil {
+		log.Fatalf("Ошибка инициализации хранилища пользователей: %v", err)
+	}
+	defer closeUsers()
+	userStore = users
+
+	projects, closeProjects, err := newProjectStore(*backend, *projectStorePath)
+	if err != nil {
+		log.Fatalf("Ошибка инициализации хранилища проектов: %v", err)
+	}
+	defer closeProjects()
+	projectStore = projects
 
-	http.HandleFunc("/api/tasks/", func(w http.ResponseWriter, r *http.Request) {
-		switch r.Method {
-		case http.MethodPut:
-			if len(r.URL.Path) > len("/api/tasks/") && r.URL.Path[len(r.URL.Path)-len("/done"):] == "/done" {
-				handleMarkTaskAsDone(w, r)
-			} else {
-				handleUpdateTask(w, r)
-			}
-		case http.MethodDelete:
-			handleDeleteTask(w, r)
-		default:
-			http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+	labels, closeLabels, err := newLabelStore(*backend, *labelStorePath)
+	if err != nil {
+		log.Fatalf("Ошибка инициализации хранилища меток: %v", err)
+	}
+	defer closeLabels()
+	labelStore = labels
+
+	templates, err := taskStore.List(TaskFilter{})
+	if err != nil {
+		log.Fatalf("Ошибка загрузки задач при запуске: %v", err)
+	}
+	for _, t := range templates {
+		if t.Schedule == nil || *t.Schedule == "" {
+			continue
+		}
+		if err := scheduler.Register(t); err != nil {
+			log.Printf("Ошибка регистрации расписания задачи %d: %v", t.ID, err)
 		}
+	}
+	scheduler.Start()
+	defer scheduler.Stop()
+
+	r := chi.NewRouter()
+
+	r.Get("/", handleRoot)
+	r.Post("/api/signup", handleSignup)
+	r.Post("/api/login", handleLogin)
+	r.Post("/api/logout", handleLogout)
+
+	r.Get("/openapi.json", handleOpenAPISpec)
+	r.Get("/docs", handleSwaggerUI)
+
+	r.Group(func(r chi.Router) {
+		r.Use(requireAuth)
+
+		r.Get("/api/tasks/stream", handleTaskStream)
+
+		r.Route("/api/projects", func(r chi.Router) {
+			r.Get("/", handleListProjects)
+			r.Post("/", handleCreateProject)
+			r.Get("/{id}/tasks", handleProjectTasks)
+		})
+
+		r.Route("/api/labels", func(r chi.Router) {
+			r.Get("/", handleListLabels)
+			r.Post("/", handleCreateLabel)
+		})
+
+		r.Route("/api/tasks", func(r chi.Router) {
+			r.Get("/", handleGetTasks)
+			r.Post("/", handleCreateTask)
+
+			r.Route("/{id}", func(r chi.Router) {
+				r.Put("/", handleUpdateTask)
+				r.Delete("/", handleDeleteTask)
+				r.Put("/done", handleMarkTaskAsDone)
+				r.Post("/schedule", handleSetTaskSchedule)
+				r.Delete("/schedule", handleDeleteTaskSchedule)
+				r.Put("/labels", handleSetTaskLabels)
+				r.Post("/attachments", handleUploadAttachment)
+				r.Get("/attachments/{aid}", handleDownloadAttachment)
+				r.Delete("/attachments/{aid}", handleDeleteAttachment)
+			})
+		})
 	})
 
 	fmt.Println("Сервер запущен на http://localhost:8080")
-	log.Fatal(http.ListenAndServe(":8080", nil))
+	log.Fatal(http.ListenAndServe(":8080", r))
 }
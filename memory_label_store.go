@@ -0,0 +1,52 @@
+package main
+
+import "sync"
+
+// MemoryLabelStore keeps labels in an in-process map.
+type MemoryLabelStore struct {
+	mu     sync.RWMutex
+	labels map[int]Label
+	nextID int
+}
+
+// NewMemoryLabelStore creates an empty in-memory LabelStore.
+func NewMemoryLabelStore() *MemoryLabelStore {
+	return &MemoryLabelStore{
+		labels: make(map[int]Label),
+		nextID: 1,
+	}
+}
+
+func (s *MemoryLabelStore) Get(id int) (Label, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	label, ok := s.labels[id]
+	if !ok {
+		return Label{}, ErrLabelNotFound
+	}
+	return label, nil
+}
+
+func (s *MemoryLabelStore) ListByOwner(ownerID int) ([]Label, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	labels := make([]Label, 0)
+	for _, label := range s.labels {
+		if label.OwnerID == ownerID {
+			labels = append(labels, label)
+		}
+	}
+	return labels, nil
+}
+
+func (s *MemoryLabelStore) Create(label Label) (Label, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	label.ID = s.nextID
+	s.labels[label.ID] = label
+	s.nextID++
+	return label, nil
+}
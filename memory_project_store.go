@@ -0,0 +1,52 @@
+package main
+
+import "sync"
+
+// MemoryProjectStore keeps projects in an in-process map.
+type MemoryProjectStore struct {
+	mu       sync.RWMutex
+	projects map[int]Project
+	nextID   int
+}
+
+// NewMemoryProjectStore creates an empty in-memory ProjectStore.
+func NewMemoryProjectStore() *MemoryProjectStore {
+	return &MemoryProjectStore{
+		projects: make(map[int]Project),
+		nextID:   1,
+	}
+}
+
+func (s *MemoryProjectStore) Get(id int) (Project, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	project, ok := s.projects[id]
+	if !ok {
+		return Project{}, ErrProjectNotFound
+	}
+	return project, nil
+}
+
+func (s *MemoryProjectStore) ListByOwner(ownerID int) ([]Project, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	projects := make([]Project, 0)
+	for _, project := range s.projects {
+		if project.OwnerID == ownerID {
+			projects = append(projects, project)
+		}
+	}
+	return projects, nil
+}
+
+func (s *MemoryProjectStore) Create(project Project) (Project, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	project.ID = s.nextID
+	s.projects[project.ID] = project
+	s.nextID++
+	return project, nil
+}
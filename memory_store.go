@@ -0,0 +1,78 @@
+package main
+
+import "sync"
+
+// MemoryTaskStore keeps tasks in an in-process map. It is lost on restart
+// and exists mainly as a lightweight backend for tests.
+type MemoryTaskStore struct {
+	mu     sync.RWMutex
+	tasks  map[int]Task
+	nextID int
+}
+
+// NewMemoryTaskStore creates an empty in-memory TaskStore.
+func NewMemoryTaskStore() *MemoryTaskStore {
+	return &MemoryTaskStore{
+		tasks:  make(map[int]Task),
+		nextID: 1,
+	}
+}
+
+func (s *MemoryTaskStore) Get(id int) (Task, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	task, ok := s.tasks[id]
+	if !ok {
+		return Task{}, ErrTaskNotFound
+	}
+	return task, nil
+}
+
+func (s *MemoryTaskStore) List(filter TaskFilter) ([]Task, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tasks := make([]Task, 0, len(s.tasks))
+	for _, task := range s.tasks {
+		if matchesFilter(task, filter) {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks, nil
+}
+
+func (s *MemoryTaskStore) Create(task Task) (Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task.ID = s.nextID
+	s.tasks[task.ID] = task
+	s.nextID++
+	return task, nil
+}
+
+func (s *MemoryTaskStore) Update(id int, fn func(*Task)) (Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, ok := s.tasks[id]
+	if !ok {
+		return Task{}, ErrTaskNotFound
+	}
+	fn(&task)
+	task.ID = id
+	s.tasks[id] = task
+	return task, nil
+}
+
+func (s *MemoryTaskStore) Delete(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.tasks[id]; !ok {
+		return ErrTaskNotFound
+	}
+	delete(s.tasks, id)
+	return nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import "sync"
+
+// MemoryUserStore keeps user accounts in an in-process map.
+type MemoryUserStore struct {
+	mu         sync.RWMutex
+	users      map[int]User
+	byUsername map[string]int
+	nextID     int
+}
+
+// NewMemoryUserStore creates an empty in-memory UserStore.
+func NewMemoryUserStore() *MemoryUserStore {
+	return &MemoryUserStore{
+		users:      make(map[int]User),
+		byUsername: make(map[string]int),
+		nextID:     1,
+	}
+}
+
+func (s *MemoryUserStore) GetByID(id int) (User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, ok := s.users[id]
+	if !ok {
+		return User{}, ErrUserNotFound
+	}
+	return user, nil
+}
+
+func (s *MemoryUserStore) GetByUsername(username string) (User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	id, ok := s.byUsername[username]
+	if !ok {
+		return User{}, ErrUserNotFound
+	}
+	return s.users[id], nil
+}
+
+func (s *MemoryUserStore) Create(username string, passwordHash []byte) (User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.byUsername[username]; exists {
+		return User{}, ErrUsernameTaken
+	}
+
+	user := User{ID: s.nextID, Username: username, PasswordHash: passwordHash}
+	s.users[user.ID] = user
+	s.byUsername[username] = user.ID
+	s.nextID++
+	return user, nil
+}
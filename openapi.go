@@ -0,0 +1,492 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// schemaRegistry accumulates named component schemas as jsonSchemaForType
+// walks struct types, so shared types (Task, User, ...) are emitted once
+// in the spec and referenced by $ref everywhere they appear.
+var schemaRegistry = map[string]map[string]interface{}{}
+
+// jsonSchemaForType converts a Go type into a JSON Schema fragment, using
+// struct json tags to name fields. Named struct types are registered in
+// schemaRegistry and returned as a $ref so the spec doesn't repeat them.
+func jsonSchemaForType(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": jsonSchemaForType(t.Elem())}
+	case reflect.Struct:
+		if t == reflect.TypeOf(time.Time{}) {
+			return map[string]interface{}{"type": "string", "format": "date-time"}
+		}
+		return schemaFor(t)
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+// schemaFor builds (and caches in schemaRegistry) the object schema for a
+// named struct type, returning a $ref to it.
+func schemaFor(t reflect.Type) map[string]interface{} {
+	name := t.Name()
+	if _, ok := schemaRegistry[name]; !ok {
+		schemaRegistry[name] = map[string]interface{}{} // reserve the slot in case of recursive types
+
+		properties := map[string]interface{}{}
+		var required []string
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			tag := field.Tag.Get("json")
+			if tag == "-" {
+				continue
+			}
+
+			fieldName := field.Name
+			omitempty := false
+			if tag != "" {
+				parts := strings.Split(tag, ",")
+				if parts[0] != "" {
+					fieldName = parts[0]
+				}
+				for _, opt := range parts[1:] {
+					if opt == "omitempty" {
+						omitempty = true
+					}
+				}
+			}
+
+			properties[fieldName] = jsonSchemaForType(field.Type)
+			if !omitempty && field.Type.Kind() != reflect.Ptr {
+				required = append(required, fieldName)
+			}
+		}
+
+		schema := map[string]interface{}{
+			"type":       "object",
+			"properties": properties,
+		}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		schemaRegistry[name] = schema
+	}
+	return ref(name)
+}
+
+func ref(schemaName string) map[string]interface{} {
+	return map[string]interface{}{"$ref": "#/components/schemas/" + schemaName}
+}
+
+func jsonRequestBody(t reflect.Type) map[string]interface{} {
+	return map[string]interface{}{
+		"required": true,
+		"content": map[string]interface{}{
+			"application/json": map[string]interface{}{"schema": jsonSchemaForType(t)},
+		},
+	}
+}
+
+func jsonResponse(description string, t reflect.Type) map[string]interface{} {
+	return map[string]interface{}{
+		"description": description,
+		"content": map[string]interface{}{
+			"application/json": map[string]interface{}{"schema": jsonSchemaForType(t)},
+		},
+	}
+}
+
+func arrayResponse(description string, t reflect.Type) map[string]interface{} {
+	return map[string]interface{}{
+		"description": description,
+		"content": map[string]interface{}{
+			"application/json": map[string]interface{}{
+				"schema": map[string]interface{}{
+					"type":  "array",
+					"items": jsonSchemaForType(t),
+				},
+			},
+		},
+	}
+}
+
+func noContentResponse(description string) map[string]interface{} {
+	return map[string]interface{}{"description": description}
+}
+
+func idParam(name, description string) map[string]interface{} {
+	return map[string]interface{}{
+		"name":        name,
+		"in":          "path",
+		"required":    true,
+		"description": description,
+		"schema":      map[string]interface{}{"type": "integer"},
+	}
+}
+
+func queryParam(name, schemaType, description string) map[string]interface{} {
+	return map[string]interface{}{
+		"name":        name,
+		"in":          "query",
+		"required":    false,
+		"description": description,
+		"schema":      map[string]interface{}{"type": schemaType},
+	}
+}
+
+// openAPIPaths hand-authors the paths object — chi's router doesn't expose
+// enough metadata (Russian-language summaries, which params are query vs
+// path) to derive this reliably, so it's kept next to buildOpenAPISpec and
+// reviewed whenever a route changes.
+func openAPIPaths() map[string]interface{} {
+	taskType := reflect.TypeOf(Task{})
+	userType := reflect.TypeOf(User{})
+	projectType := reflect.TypeOf(Project{})
+	labelType := reflect.TypeOf(Label{})
+	attachmentType := reflect.TypeOf(Attachment{})
+	credentialsType := reflect.TypeOf(credentials{})
+
+	return map[string]interface{}{
+		"/api/signup": map[string]interface{}{
+			"post": map[string]interface{}{
+				"summary":     "Зарегистрировать нового пользователя",
+				"tags":        []string{"auth"},
+				"requestBody": jsonRequestBody(credentialsType),
+				"responses": map[string]interface{}{
+					"201": jsonResponse("Пользователь создан, сессия открыта", userType),
+					"400": noContentResponse("Неверное тело запроса"),
+					"409": noContentResponse("Имя пользователя уже занято"),
+				},
+			},
+		},
+		"/api/login": map[string]interface{}{
+			"post": map[string]interface{}{
+				"summary":     "Войти в систему",
+				"tags":        []string{"auth"},
+				"requestBody": jsonRequestBody(credentialsType),
+				"responses": map[string]interface{}{
+					"200": jsonResponse("Сессия открыта", userType),
+					"401": noContentResponse("Неверное имя пользователя или пароль"),
+				},
+			},
+		},
+		"/api/logout": map[string]interface{}{
+			"post": map[string]interface{}{
+				"summary": "Выйти из системы",
+				"tags":    []string{"auth"},
+				"responses": map[string]interface{}{
+					"204": noContentResponse("Сессия закрыта"),
+				},
+			},
+		},
+		"/api/tasks": map[string]interface{}{
+			"get": map[string]interface{}{
+				"summary": "Получить список задач",
+				"tags":    []string{"tasks"},
+				"parameters": []interface{}{
+					queryParam("completed", "boolean", "Фильтр по статусу выполнения"),
+					queryParam("project", "integer", "Фильтр по ID проекта"),
+					queryParam("label", "integer", "Фильтр по ID метки"),
+					queryParam("due_before", "string", "Срок до указанной даты (RFC3339)"),
+					queryParam("due_after", "string", "Срок после указанной даты (RFC3339)"),
+					queryParam("priority_min", "integer", "Минимальный приоритет"),
+					queryParam("sort", "string", "Поле сортировки: due, priority или created"),
+					queryParam("order", "string", "Направление сортировки: asc или desc"),
+					queryParam("limit", "integer", "Максимальное число задач в ответе"),
+					queryParam("offset", "integer", "Смещение для пагинации"),
+				},
+				"responses": map[string]interface{}{
+					"200": arrayResponse("Список задач владельца", taskType),
+					"400": noContentResponse("Неверное значение параметра запроса"),
+				},
+			},
+			"post": map[string]interface{}{
+				"summary":     "Создать задачу",
+				"tags":        []string{"tasks"},
+				"requestBody": jsonRequestBody(taskType),
+				"responses": map[string]interface{}{
+					"201": jsonResponse("Задача создана", taskType),
+					"400": noContentResponse("Неверное тело запроса"),
+				},
+			},
+		},
+		"/api/tasks/{id}": map[string]interface{}{
+			"put": map[string]interface{}{
+				"summary":     "Обновить задачу",
+				"tags":        []string{"tasks"},
+				"parameters":  []interface{}{idParam("id", "ID задачи")},
+				"requestBody": jsonRequestBody(taskType),
+				"responses": map[string]interface{}{
+					"200": jsonResponse("Задача обновлена", taskType),
+					"404": noContentResponse("Задача не найдена"),
+				},
+			},
+			"delete": map[string]interface{}{
+				"summary":    "Удалить задачу",
+				"tags":       []string{"tasks"},
+				"parameters": []interface{}{idParam("id", "ID задачи")},
+				"responses": map[string]interface{}{
+					"204": noContentResponse("Задача удалена"),
+					"404": noContentResponse("Задача не найдена"),
+				},
+			},
+		},
+		"/api/tasks/{id}/done": map[string]interface{}{
+			"put": map[string]interface{}{
+				"summary":    "Отметить задачу выполненной",
+				"tags":       []string{"tasks"},
+				"parameters": []interface{}{idParam("id", "ID задачи")},
+				"responses": map[string]interface{}{
+					"200": jsonResponse("Задача отмечена выполненной", taskType),
+					"404": noContentResponse("Задача не найдена"),
+				},
+			},
+		},
+		"/api/tasks/{id}/schedule": map[string]interface{}{
+			"post": map[string]interface{}{
+				"summary":    "Назначить задаче расписание cron",
+				"tags":       []string{"tasks"},
+				"parameters": []interface{}{idParam("id", "ID задачи")},
+				"requestBody": jsonRequestBody(reflect.TypeOf(scheduleRequest{})),
+				"responses": map[string]interface{}{
+					"200": jsonResponse("Расписание задачи обновлено", taskType),
+					"400": noContentResponse("Неверное выражение cron"),
+					"404": noContentResponse("Задача не найдена"),
+				},
+			},
+			"delete": map[string]interface{}{
+				"summary":    "Снять расписание с задачи",
+				"tags":       []string{"tasks"},
+				"parameters": []interface{}{idParam("id", "ID задачи")},
+				"responses": map[string]interface{}{
+					"200": jsonResponse("Расписание снято", taskType),
+					"404": noContentResponse("Задача не найдена"),
+				},
+			},
+		},
+		"/api/tasks/{id}/labels": map[string]interface{}{
+			"put": map[string]interface{}{
+				"summary":    "Заменить набор меток задачи",
+				"tags":       []string{"tasks"},
+				"parameters": []interface{}{idParam("id", "ID задачи")},
+				"responses": map[string]interface{}{
+					"200": jsonResponse("Метки задачи обновлены", taskType),
+					"400": noContentResponse("Метка не найдена"),
+					"404": noContentResponse("Задача не найдена"),
+				},
+			},
+		},
+		"/api/tasks/{id}/attachments": map[string]interface{}{
+			"post": map[string]interface{}{
+				"summary":    "Загрузить вложение к задаче",
+				"tags":       []string{"tasks"},
+				"parameters": []interface{}{idParam("id", "ID задачи")},
+				"requestBody": map[string]interface{}{
+					"required": true,
+					"content": map[string]interface{}{
+						"multipart/form-data": map[string]interface{}{
+							"schema": map[string]interface{}{
+								"type": "object",
+								"properties": map[string]interface{}{
+									"file": map[string]interface{}{"type": "string", "format": "binary"},
+								},
+							},
+						},
+					},
+				},
+				"responses": map[string]interface{}{
+					"201": jsonResponse("Вложение сохранено", attachmentType),
+					"400": noContentResponse("Ожидается multipart/form-data с полем file"),
+					"413": noContentResponse("Файл превышает допустимый размер"),
+				},
+			},
+		},
+		"/api/tasks/{id}/attachments/{aid}": map[string]interface{}{
+			"get": map[string]interface{}{
+				"summary": "Скачать вложение",
+				"tags":    []string{"tasks"},
+				"parameters": []interface{}{
+					idParam("id", "ID задачи"),
+					map[string]interface{}{
+						"name": "aid", "in": "path", "required": true,
+						"description": "ID вложения",
+						"schema":      map[string]interface{}{"type": "string"},
+					},
+				},
+				"responses": map[string]interface{}{
+					"200": map[string]interface{}{
+						"description": "Содержимое файла",
+						"content": map[string]interface{}{
+							"application/octet-stream": map[string]interface{}{
+								"schema": map[string]interface{}{"type": "string", "format": "binary"},
+							},
+						},
+					},
+					"404": noContentResponse("Вложение не найдено"),
+				},
+			},
+			"delete": map[string]interface{}{
+				"summary": "Удалить вложение",
+				"tags":    []string{"tasks"},
+				"parameters": []interface{}{
+					idParam("id", "ID задачи"),
+					map[string]interface{}{
+						"name": "aid", "in": "path", "required": true,
+						"description": "ID вложения",
+						"schema":      map[string]interface{}{"type": "string"},
+					},
+				},
+				"responses": map[string]interface{}{
+					"204": noContentResponse("Вложение удалено"),
+					"404": noContentResponse("Вложение не найдено"),
+				},
+			},
+		},
+		"/api/tasks/stream": map[string]interface{}{
+			"get": map[string]interface{}{
+				"summary": "Поток событий по задачам владельца (WebSocket)",
+				"tags":    []string{"tasks"},
+				"parameters": []interface{}{
+					queryParam("since", "integer", "Порядковый номер события, начиная с которого повторить пропущенные события"),
+				},
+				"responses": map[string]interface{}{
+					"101": noContentResponse("Переключение на протокол WebSocket"),
+				},
+			},
+		},
+		"/api/projects": map[string]interface{}{
+			"get": map[string]interface{}{
+				"summary": "Получить список проектов",
+				"tags":    []string{"projects"},
+				"responses": map[string]interface{}{
+					"200": arrayResponse("Список проектов владельца", projectType),
+				},
+			},
+			"post": map[string]interface{}{
+				"summary":     "Создать проект",
+				"tags":        []string{"projects"},
+				"requestBody": jsonRequestBody(projectType),
+				"responses": map[string]interface{}{
+					"201": jsonResponse("Проект создан", projectType),
+					"400": noContentResponse("Неверное тело запроса"),
+				},
+			},
+		},
+		"/api/projects/{id}/tasks": map[string]interface{}{
+			"get": map[string]interface{}{
+				"summary":    "Получить задачи проекта",
+				"tags":       []string{"projects"},
+				"parameters": []interface{}{idParam("id", "ID проекта")},
+				"responses": map[string]interface{}{
+					"200": arrayResponse("Задачи проекта", taskType),
+					"404": noContentResponse("Проект не найден"),
+				},
+			},
+		},
+		"/api/labels": map[string]interface{}{
+			"get": map[string]interface{}{
+				"summary": "Получить список меток",
+				"tags":    []string{"labels"},
+				"responses": map[string]interface{}{
+					"200": arrayResponse("Список меток владельца", labelType),
+				},
+			},
+			"post": map[string]interface{}{
+				"summary":     "Создать метку",
+				"tags":        []string{"labels"},
+				"requestBody": jsonRequestBody(labelType),
+				"responses": map[string]interface{}{
+					"201": jsonResponse("Метка создана", labelType),
+					"400": noContentResponse("Неверное тело запроса"),
+				},
+			},
+		},
+	}
+}
+
+var (
+	openAPISpecOnce sync.Once
+	openAPISpec     map[string]interface{}
+)
+
+// buildOpenAPISpec assembles the full OpenAPI 3 document, building it only
+// once: component schemas are populated as a side effect of calling
+// jsonSchemaForType from openAPIPaths, which mutates the package-level
+// schemaRegistry, so concurrent first requests would otherwise race on it.
+func buildOpenAPISpec() map[string]interface{} {
+	openAPISpecOnce.Do(func() {
+		paths := openAPIPaths()
+
+		openAPISpec = map[string]interface{}{
+			"openapi": "3.0.3",
+			"info": map[string]interface{}{
+				"title":       "Список Задач API",
+				"version":     "1.0.0",
+				"description": "API для управления задачами, проектами, метками и вложениями.",
+			},
+			"components": map[string]interface{}{
+				"schemas": schemaRegistry,
+				"securitySchemes": map[string]interface{}{
+					"sessionCookie": map[string]interface{}{
+						"type": "apiKey",
+						"in":   "cookie",
+						"name": sessionCookieName,
+					},
+				},
+			},
+			"paths": paths,
+		}
+	})
+	return openAPISpec
+}
+
+// handleOpenAPISpec serves GET /openapi.json.
+func handleOpenAPISpec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(buildOpenAPISpec())
+}
+
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="ru">
+<head>
+  <meta charset="utf-8">
+  <title>Список Задач API — документация</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist/swagger-ui-bundle.js"></script>
+  <script>
+    window.onload = function() {
+      SwaggerUIBundle({
+        url: "/openapi.json",
+        dom_id: "#swagger-ui",
+      });
+    };
+  </script>
+</body>
+</html>
+`
+
+// handleSwaggerUI serves GET /docs, a static page that renders the spec
+// from /openapi.json via Swagger UI.
+func handleSwaggerUI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(swaggerUIPage))
+}
@@ -0,0 +1,25 @@
+package main
+
+import "errors"
+
+// ErrProjectNotFound is returned by ProjectStore implementations when no
+// project matches the requested ID.
+var ErrProjectNotFound = errors.New("проект не найден")
+
+// Project groups tasks under a named, per-user workspace.
+type Project struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	OwnerID int    `json:"ownerId"`
+}
+
+// ProjectStore persists projects. Implementations must be safe for
+// concurrent use.
+type ProjectStore interface {
+	// Get returns the project with the given ID, or ErrProjectNotFound.
+	Get(id int) (Project, error)
+	// ListByOwner returns every project owned by ownerID.
+	ListByOwner(ownerID int) ([]Project, error)
+	// Create assigns an ID to project and persists it.
+	Create(project Project) (Project, error)
+}
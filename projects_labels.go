@@ -0,0 +1,203 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+var projectStore ProjectStore
+var labelStore LabelStore
+
+// errProjectOwnership is returned by validateProjectID when the project
+// does not exist or does not belong to the requesting owner.
+var errProjectOwnership = errors.New("проект не найден")
+
+// errLabelOwnership is returned by validateLabelIDs when a label does not
+// exist or does not belong to the requesting owner.
+var errLabelOwnership = errors.New("метка не найдена")
+
+// validateProjectID checks that projectID (if set) names a project owned
+// by ownerID, so a task can't be filed under another user's project.
+func validateProjectID(ownerID int, projectID *int) error {
+	if projectID == nil {
+		return nil
+	}
+	project, err := projectStore.Get(*projectID)
+	if err == ErrProjectNotFound || (err == nil && project.OwnerID != ownerID) {
+		return errProjectOwnership
+	}
+	return err
+}
+
+// validateLabelIDs checks that every ID in labelIDs names a label owned by
+// ownerID, so a task can't be tagged with another user's labels.
+func validateLabelIDs(ownerID int, labelIDs []int) error {
+	for _, labelID := range labelIDs {
+		label, err := labelStore.Get(labelID)
+		if err == ErrLabelNotFound || (err == nil && label.OwnerID != ownerID) {
+			return errLabelOwnership
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func handleListProjects(w http.ResponseWriter, r *http.Request) {
+	ownerID := userFromContext(r.Context()).ID
+	projects, err := projectStore.ListByOwner(ownerID)
+	if err != nil {
+		log.Printf("Ошибка получения проектов: %v", err)
+		http.Error(w, "Не удалось получить проекты", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(projects)
+}
+
+func handleCreateProject(w http.ResponseWriter, r *http.Request) {
+	var project Project
+	if err := json.NewDecoder(r.Body).Decode(&project); err != nil {
+		log.Printf("Ошибка декодирования JSON: %v", err)
+		http.Error(w, "Неверное тело запроса", http.StatusBadRequest)
+		return
+	}
+	if project.Name == "" {
+		http.Error(w, "Укажите название проекта", http.StatusBadRequest)
+		return
+	}
+	project.OwnerID = userFromContext(r.Context()).ID
+
+	project, err := projectStore.Create(project)
+	if err != nil {
+		log.Printf("Ошибка создания проекта: %v", err)
+		http.Error(w, "Не удалось создать проект", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(project)
+}
+
+// handleProjectTasks serves GET /api/projects/{id}/tasks.
+func handleProjectTasks(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Неверный ID проекта", http.StatusBadRequest)
+		return
+	}
+
+	ownerID := userFromContext(r.Context()).ID
+	project, err := projectStore.Get(id)
+	if err == ErrProjectNotFound || (err == nil && project.OwnerID != ownerID) {
+		http.Error(w, "Проект не найден", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Ошибка получения проекта: %v", err)
+		http.Error(w, "Не удалось получить проект", http.StatusInternalServerError)
+		return
+	}
+
+	tasks, err := taskStore.List(TaskFilter{ProjectID: &id})
+	if err != nil {
+		log.Printf("Ошибка получения задач проекта: %v", err)
+		http.Error(w, "Не удалось получить задачи", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tasks)
+}
+
+func handleListLabels(w http.ResponseWriter, r *http.Request) {
+	ownerID := userFromContext(r.Context()).ID
+	labels, err := labelStore.ListByOwner(ownerID)
+	if err != nil {
+		log.Printf("Ошибка получения меток: %v", err)
+		http.Error(w, "Не удалось получить метки", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(labels)
+}
+
+func handleCreateLabel(w http.ResponseWriter, r *http.Request) {
+	var label Label
+	if err := json.NewDecoder(r.Body).Decode(&label); err != nil {
+		log.Printf("Ошибка декодирования JSON: %v", err)
+		http.Error(w, "Неверное тело запроса", http.StatusBadRequest)
+		return
+	}
+	if label.Name == "" {
+		http.Error(w, "Укажите название метки", http.StatusBadRequest)
+		return
+	}
+	label.OwnerID = userFromContext(r.Context()).ID
+
+	label, err := labelStore.Create(label)
+	if err != nil {
+		log.Printf("Ошибка создания метки: %v", err)
+		http.Error(w, "Не удалось создать метку", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(label)
+}
+
+// handleSetTaskLabels serves PUT /api/tasks/{id}/labels, replacing a
+// task's label set wholesale.
+func handleSetTaskLabels(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Неверный ID задачи", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		LabelIDs []int `json:"labelIds"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Ошибка декодирования JSON: %v", err)
+		http.Error(w, "Неверное тело запроса", http.StatusBadRequest)
+		return
+	}
+
+	ownerID := userFromContext(r.Context()).ID
+	if _, err := checkTaskOwnership(id, ownerID); err != nil {
+		writeTaskOwnershipError(w, err)
+		return
+	}
+
+	if err := validateLabelIDs(ownerID, req.LabelIDs); err != nil {
+		if err == errLabelOwnership {
+			http.Error(w, "Метка не найдена", http.StatusBadRequest)
+			return
+		}
+		log.Printf("Ошибка получения метки: %v", err)
+		http.Error(w, "Не удалось обновить метки задачи", http.StatusInternalServerError)
+		return
+	}
+
+	task, err := taskStore.Update(id, func(t *Task) {
+		t.LabelIDs = req.LabelIDs
+	})
+	if err != nil {
+		log.Printf("Ошибка обновления задачи: %v", err)
+		http.Error(w, "Не удалось обновить задачу", http.StatusInternalServerError)
+		return
+	}
+
+	eventHub.Publish(Event{Type: EventUpdated, Task: task})
+
+	json.NewEncoder(w).Encode(task)
+}
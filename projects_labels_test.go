@@ -0,0 +1,132 @@
+package main
+
+import "testing"
+
+func withTestProjectAndLabelStores(t *testing.T) {
+	t.Helper()
+	prevProjects, prevLabels := projectStore, labelStore
+	projectStore = NewMemoryProjectStore()
+	labelStore = NewMemoryLabelStore()
+	t.Cleanup(func() {
+		projectStore = prevProjects
+		labelStore = prevLabels
+	})
+}
+
+func TestValidateProjectIDAllowsNil(t *testing.T) {
+	withTestProjectAndLabelStores(t)
+
+	if err := validateProjectID(1, nil); err != nil {
+		t.Fatalf("ожидалось отсутствие ошибки для nil projectID, получено: %v", err)
+	}
+}
+
+func TestValidateProjectIDRejectsOtherOwnersProject(t *testing.T) {
+	withTestProjectAndLabelStores(t)
+
+	project, err := projectStore.Create(Project{Name: "Чужой проект", OwnerID: 2})
+	if err != nil {
+		t.Fatalf("создание проекта: %v", err)
+	}
+
+	if err := validateProjectID(1, &project.ID); err != errProjectOwnership {
+		t.Fatalf("ожидалась errProjectOwnership, получено: %v", err)
+	}
+}
+
+func TestValidateProjectIDRejectsMissingProject(t *testing.T) {
+	withTestProjectAndLabelStores(t)
+
+	missingID := 999
+	if err := validateProjectID(1, &missingID); err != errProjectOwnership {
+		t.Fatalf("ожидалась errProjectOwnership, получено: %v", err)
+	}
+}
+
+func TestValidateProjectIDAllowsOwnProject(t *testing.T) {
+	withTestProjectAndLabelStores(t)
+
+	project, err := projectStore.Create(Project{Name: "Мой проект", OwnerID: 1})
+	if err != nil {
+		t.Fatalf("создание проекта: %v", err)
+	}
+
+	if err := validateProjectID(1, &project.ID); err != nil {
+		t.Fatalf("ожидалось отсутствие ошибки для собственного проекта, получено: %v", err)
+	}
+}
+
+func TestValidateLabelIDsRejectsOtherOwnersLabel(t *testing.T) {
+	withTestProjectAndLabelStores(t)
+
+	ownLabel, err := labelStore.Create(Label{Name: "своя", OwnerID: 1})
+	if err != nil {
+		t.Fatalf("создание метки: %v", err)
+	}
+	otherLabel, err := labelStore.Create(Label{Name: "чужая", OwnerID: 2})
+	if err != nil {
+		t.Fatalf("создание метки: %v", err)
+	}
+
+	if err := validateLabelIDs(1, []int{ownLabel.ID, otherLabel.ID}); err != errLabelOwnership {
+		t.Fatalf("ожидалась errLabelOwnership, получено: %v", err)
+	}
+}
+
+func TestValidateLabelIDsRejectsMissingLabel(t *testing.T) {
+	withTestProjectAndLabelStores(t)
+
+	if err := validateLabelIDs(1, []int{999}); err != errLabelOwnership {
+		t.Fatalf("ожидалась errLabelOwnership, получено: %v", err)
+	}
+}
+
+func TestValidateLabelIDsAllowsOwnLabelsAndEmpty(t *testing.T) {
+	withTestProjectAndLabelStores(t)
+
+	label, err := labelStore.Create(Label{Name: "своя", OwnerID: 1})
+	if err != nil {
+		t.Fatalf("создание метки: %v", err)
+	}
+
+	if err := validateLabelIDs(1, []int{label.ID}); err != nil {
+		t.Fatalf("ожидалось отсутствие ошибки для собственной метки, получено: %v", err)
+	}
+	if err := validateLabelIDs(1, nil); err != nil {
+		t.Fatalf("ожидалось отсутствие ошибки для пустого списка меток, получено: %v", err)
+	}
+}
+
+func TestCheckTaskOwnershipRejectsOtherOwnersTask(t *testing.T) {
+	prevTasks := taskStore
+	taskStore = NewMemoryTaskStore()
+	t.Cleanup(func() { taskStore = prevTasks })
+
+	task, err := taskStore.Create(Task{Title: "Чужая задача", OwnerID: 2})
+	if err != nil {
+		t.Fatalf("создание задачи: %v", err)
+	}
+
+	if _, err := checkTaskOwnership(task.ID, 1); err != ErrTaskNotFound {
+		t.Fatalf("ожидалась ErrTaskNotFound, получено: %v", err)
+	}
+}
+
+func TestCheckTaskOwnershipAllowsOwnTask(t *testing.T) {
+	prevTasks := taskStore
+	taskStore = NewMemoryTaskStore()
+	t.Cleanup(func() { taskStore = prevTasks })
+
+	task, err := taskStore.Create(Task{Title: "Своя задача", OwnerID: 1})
+	if err != nil {
+		t.Fatalf("создание задачи: %v", err)
+	}
+
+	got, err := checkTaskOwnership(task.ID, 1)
+	if err != nil {
+		t.Fatalf("ожидалось отсутствие ошибки, получено: %v", err)
+	}
+	if got.ID != task.ID {
+		t.Fatalf("ожидалась задача %d, получена %d", task.ID, got.ID)
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Scheduler materializes new Task instances from recurring templates
+// (tasks with a non-empty Schedule) according to their cron expression.
+type Scheduler struct {
+	cron *cron.Cron
+
+	mu      sync.Mutex
+	entries map[int]cron.EntryID
+}
+
+// NewScheduler creates a Scheduler. Call Start to begin firing schedules.
+func NewScheduler() *Scheduler {
+	return &Scheduler{
+		cron:    cron.New(),
+		entries: make(map[int]cron.EntryID),
+	}
+}
+
+// Start begins running registered schedules in the background.
+func (s *Scheduler) Start() {
+	s.cron.Start()
+}
+
+// Stop halts the scheduler, waiting for any in-flight tick to finish.
+func (s *Scheduler) Stop() {
+	s.cron.Stop()
+}
+
+// Register (re)schedules template so it materializes a new instance on
+// every tick of its Schedule, replacing any previous registration for the
+// same task ID.
+func (s *Scheduler) Register(template Task) error {
+	if template.Schedule == nil || *template.Schedule == "" {
+		return fmt.Errorf("у задачи %d не задано расписание", template.ID)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entryID, ok := s.entries[template.ID]; ok {
+		s.cron.Remove(entryID)
+		delete(s.entries, template.ID)
+	}
+
+	templateID := template.ID
+	entryID, err := s.cron.AddFunc(*template.Schedule, func() {
+		materializeTask(templateID)
+	})
+	if err != nil {
+		return fmt.Errorf("неверное расписание %q: %w", *template.Schedule, err)
+	}
+	s.entries[template.ID] = entryID
+	return nil
+}
+
+// Unregister stops firing the schedule for templateID, if any is active.
+func (s *Scheduler) Unregister(templateID int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entryID, ok := s.entries[templateID]; ok {
+		s.cron.Remove(entryID)
+		delete(s.entries, templateID)
+	}
+}
+
+// materializeTask creates a new instance of the recurring template
+// identified by templateID, unless an uncompleted instance from it
+// already exists.
+func materializeTask(templateID int) {
+	template, err := taskStore.Get(templateID)
+	if err != nil {
+		log.Printf("Ошибка получения шаблона задачи %d: %v", templateID, err)
+		return
+	}
+
+	notCompleted := false
+	existing, err := taskStore.List(TaskFilter{ParentID: &templateID, Completed: &notCompleted})
+	if err != nil {
+		log.Printf("Ошибка проверки экземпляров задачи %d: %v", templateID, err)
+		return
+	}
+	if len(existing) > 0 {
+		return
+	}
+
+	instance := Task{
+		Title:     template.Title,
+		OwnerID:   template.OwnerID,
+		ParentID:  &templateID,
+		CreatedAt: time.Now(),
+		Priority:  template.Priority,
+		ProjectID: template.ProjectID,
+	}
+	instance, err = taskStore.Create(instance)
+	if err != nil {
+		log.Printf("Ошибка создания повторяющейся задачи из шаблона %d: %v", templateID, err)
+		return
+	}
+
+	eventHub.Publish(Event{Type: EventCreated, Task: instance})
+}
+
+var scheduler = NewScheduler()
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// sessionTTL is how long a session stays valid after login without being
+// refreshed.
+const sessionTTL = 24 * time.Hour
+
+// Session binds a random, unguessable session ID to a user and carries a
+// CSRF token that must accompany mutating requests made with that session.
+type Session struct {
+	ID        string
+	UserID    int
+	CSRFToken string
+	ExpiresAt time.Time
+}
+
+func (s Session) expired() bool {
+	return time.Now().After(s.ExpiresAt)
+}
+
+// SessionStore keeps server-side session state. Sessions live only for the
+// life of the process; there is no persistence backend for them.
+type SessionStore struct {
+	mu       sync.RWMutex
+	sessions map[string]Session
+}
+
+// NewSessionStore creates an empty SessionStore.
+func NewSessionStore() *SessionStore {
+	return &SessionStore{sessions: make(map[string]Session)}
+}
+
+// Create starts a new session for userID and returns it.
+func (s *SessionStore) Create(userID int) (Session, error) {
+	id, err := randomToken()
+	if err != nil {
+		return Session{}, err
+	}
+	csrfToken, err := randomToken()
+	if err != nil {
+		return Session{}, err
+	}
+
+	session := Session{
+		ID:        id,
+		UserID:    userID,
+		CSRFToken: csrfToken,
+		ExpiresAt: time.Now().Add(sessionTTL),
+	}
+
+	s.mu.Lock()
+	s.sessions[session.ID] = session
+	s.mu.Unlock()
+
+	return session, nil
+}
+
+// Get returns the session with the given ID, if it exists and has not
+// expired.
+func (s *SessionStore) Get(id string) (Session, bool) {
+	s.mu.RLock()
+	session, ok := s.sessions[id]
+	s.mu.RUnlock()
+
+	if !ok || session.expired() {
+		return Session{}, false
+	}
+	return session, true
+}
+
+// Delete ends the session with the given ID.
+func (s *SessionStore) Delete(id string) {
+	s.mu.Lock()
+	delete(s.sessions, id)
+	s.mu.Unlock()
+}
+
+func randomToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
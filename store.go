@@ -0,0 +1,47 @@
+package main
+
+import "errors"
+
+// ErrTaskNotFound is returned by TaskStore implementations when a task
+// with the requested ID does not exist.
+var ErrTaskNotFound = errors.New("задача не найдена")
+
+// TaskFilter narrows down the result of TaskStore.List. A nil field means
+// "don't filter on this".
+type TaskFilter struct {
+	Completed *bool
+	OwnerID   *int
+	ParentID  *int
+	ProjectID *int
+}
+
+// TaskStore persists tasks. Implementations must be safe for concurrent use.
+type TaskStore interface {
+	// Get returns the task with the given ID, or ErrTaskNotFound.
+	Get(id int) (Task, error)
+	// List returns all tasks matching filter.
+	List(filter TaskFilter) ([]Task, error)
+	// Create assigns an ID to task and persists it.
+	Create(task Task) (Task, error)
+	// Update applies fn to the stored task with the given ID and persists
+	// the result, or returns ErrTaskNotFound.
+	Update(id int, fn func(*Task)) (Task, error)
+	// Delete removes the task with the given ID, or returns ErrTaskNotFound.
+	Delete(id int) error
+}
+
+func matchesFilter(task Task, filter TaskFilter) bool {
+	if filter.Completed != nil && task.Completed != *filter.Completed {
+		return false
+	}
+	if filter.OwnerID != nil && task.OwnerID != *filter.OwnerID {
+		return false
+	}
+	if filter.ParentID != nil && (task.ParentID == nil || *task.ParentID != *filter.ParentID) {
+		return false
+	}
+	if filter.ProjectID != nil && (task.ProjectID == nil || *task.ProjectID != *filter.ProjectID) {
+		return false
+	}
+	return true
+}
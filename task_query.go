@@ -0,0 +1,193 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// taskListOptions are the extra query-string knobs handleGetTasks accepts
+// beyond the store-level TaskFilter: a label membership check, due-date
+// bounds, a priority floor, a sort key/direction, and pagination.
+type taskListOptions struct {
+	LabelID     *int
+	DueBefore   *time.Time
+	DueAfter    *time.Time
+	PriorityMin *int
+	Sort        string
+	Order       string
+	Limit       *int
+	Offset      int
+}
+
+func parseTaskListOptions(q url.Values) (taskListOptions, error) {
+	var opts taskListOptions
+
+	if raw := q.Get("label"); raw != "" {
+		id, err := strconv.Atoi(raw)
+		if err != nil {
+			return opts, errInvalidParam("label")
+		}
+		opts.LabelID = &id
+	}
+
+	if raw := q.Get("due_before"); raw != "" {
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return opts, errInvalidParam("due_before")
+		}
+		opts.DueBefore = &t
+	}
+
+	if raw := q.Get("due_after"); raw != "" {
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return opts, errInvalidParam("due_after")
+		}
+		opts.DueAfter = &t
+	}
+
+	if raw := q.Get("priority_min"); raw != "" {
+		min, err := strconv.Atoi(raw)
+		if err != nil {
+			return opts, errInvalidParam("priority_min")
+		}
+		opts.PriorityMin = &min
+	}
+
+	opts.Sort = q.Get("sort")
+	switch opts.Sort {
+	case "", "due", "priority", "created":
+	default:
+		return opts, errInvalidParam("sort")
+	}
+
+	opts.Order = q.Get("order")
+	switch opts.Order {
+	case "":
+		opts.Order = "asc"
+	case "asc", "desc":
+	default:
+		return opts, errInvalidParam("order")
+	}
+
+	if raw := q.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 0 {
+			return opts, errInvalidParam("limit")
+		}
+		opts.Limit = &limit
+	}
+
+	if raw := q.Get("offset"); raw != "" {
+		offset, err := strconv.Atoi(raw)
+		if err != nil || offset < 0 {
+			return opts, errInvalidParam("offset")
+		}
+		opts.Offset = offset
+	}
+
+	return opts, nil
+}
+
+type invalidParamError struct {
+	param string
+}
+
+func errInvalidParam(param string) error {
+	return invalidParamError{param: param}
+}
+
+func (e invalidParamError) Error() string {
+	return "неверное значение параметра " + e.param
+}
+
+// applyTaskListOptions filters, sorts, and paginates tasks per opts. It
+// covers everything TaskFilter can't express as a store-level index
+// lookup (label membership, date ranges, a priority floor, ordering).
+func applyTaskListOptions(tasks []Task, opts taskListOptions) []Task {
+	filtered := tasks[:0:0]
+	for _, t := range tasks {
+		if opts.LabelID != nil && !hasLabel(t, *opts.LabelID) {
+			continue
+		}
+		if opts.DueBefore != nil && (t.DueDate == nil || !t.DueDate.Before(*opts.DueBefore)) {
+			continue
+		}
+		if opts.DueAfter != nil && (t.DueDate == nil || !t.DueDate.After(*opts.DueAfter)) {
+			continue
+		}
+		if opts.PriorityMin != nil && t.Priority < *opts.PriorityMin {
+			continue
+		}
+		filtered = append(filtered, t)
+	}
+
+	sortTasks(filtered, opts.Sort, opts.Order)
+
+	if opts.Limit == nil {
+		if opts.Offset >= len(filtered) {
+			return []Task{}
+		}
+		return filtered[opts.Offset:]
+	}
+
+	start := opts.Offset
+	if start > len(filtered) {
+		start = len(filtered)
+	}
+	end := start + *opts.Limit
+	if end > len(filtered) {
+		end = len(filtered)
+	}
+	return filtered[start:end]
+}
+
+func hasLabel(t Task, labelID int) bool {
+	for _, id := range t.LabelIDs {
+		if id == labelID {
+			return true
+		}
+	}
+	return false
+}
+
+func sortTasks(tasks []Task, key, order string) {
+	if key == "" {
+		return
+	}
+
+	less := func(i, j int) bool {
+		switch key {
+		case "due":
+			return taskDueOrZero(tasks[i]).Before(taskDueOrZero(tasks[j]))
+		case "priority":
+			return tasks[i].Priority < tasks[j].Priority
+		case "created":
+			return tasks[i].CreatedAt.Before(tasks[j].CreatedAt)
+		default:
+			return false
+		}
+	}
+	if order == "desc" {
+		wrapped := less
+		less = func(i, j int) bool { return wrapped(j, i) }
+	}
+	sort.SliceStable(tasks, less)
+}
+
+func taskDueOrZero(t Task) time.Time {
+	if t.DueDate == nil {
+		return time.Time{}
+	}
+	return *t.DueDate
+}
+
+// writeInvalidParamError is a small helper so handleGetTasks can turn a
+// parseTaskListOptions error into the same 400 response style as the rest
+// of the API.
+func writeInvalidParamError(w http.ResponseWriter, err error) {
+	http.Error(w, err.Error(), http.StatusBadRequest)
+}
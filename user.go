@@ -0,0 +1,31 @@
+package main
+
+import "errors"
+
+// ErrUserNotFound is returned by UserStore implementations when no user
+// matches the requested ID or username.
+var ErrUserNotFound = errors.New("пользователь не найден")
+
+// ErrUsernameTaken is returned by UserStore.Create when the username is
+// already registered.
+var ErrUsernameTaken = errors.New("имя пользователя уже занято")
+
+// User is an account that owns tasks. PasswordHash is a bcrypt hash and is
+// never serialized back to clients.
+type User struct {
+	ID           int    `json:"id"`
+	Username     string `json:"username"`
+	PasswordHash []byte `json:"-"`
+}
+
+// UserStore persists user accounts. Implementations must be safe for
+// concurrent use.
+type UserStore interface {
+	// GetByID returns the user with the given ID, or ErrUserNotFound.
+	GetByID(id int) (User, error)
+	// GetByUsername returns the user with the given username, or
+	// ErrUserNotFound.
+	GetByUsername(username string) (User, error)
+	// Create registers a new user, or returns ErrUsernameTaken.
+	Create(username string, passwordHash []byte) (User, error)
+}
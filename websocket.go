@@ -0,0 +1,137 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	wsPongWait   = 60 * time.Second
+	wsPingPeriod = (wsPongWait * 9) / 10
+)
+
+var wsUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	// Браузер прикрепляет сессионные cookie и к кросс-доменным
+	// WebSocket-хендшейкам, а сам хендшейк — GET-запрос, так что
+	// requireAuth его CSRF-проверкой не покрывает. Поэтому здесь нужно
+	// явно сверять Origin с хостом, на котором поднят сервер.
+	CheckOrigin: func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			return true
+		}
+		u, err := url.Parse(origin)
+		if err != nil {
+			return false
+		}
+		return u.Host == r.Host
+	},
+}
+
+// handleTaskStream upgrades the connection to a WebSocket and streams task
+// change events for the authenticated user. A `since` query parameter
+// replays buffered events the client missed while disconnected.
+func handleTaskStream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	since := 0
+	if raw := r.URL.Query().Get("since"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			http.Error(w, "Неверное значение параметра since", http.StatusBadRequest)
+			return
+		}
+		since = parsed
+	}
+
+	ownerID := userFromContext(r.Context()).ID
+
+	conn, err := wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("Ошибка обновления соединения до WebSocket: %v", err)
+		return
+	}
+
+	id, err := randomToken()
+	if err != nil {
+		log.Printf("Ошибка генерации ID клиента: %v", err)
+		conn.Close()
+		return
+	}
+
+	c := &client{id: id, ownerID: ownerID, send: make(chan Event, 16)}
+	eventHub.Register(c)
+
+	// Replayed events are sent on a separate goroutine because writePump is
+	// the only reader of c.send; sending here directly, before writePump
+	// starts draining it, would deadlock once more than 16 events (the
+	// channel's buffer) were missed. c.deliver guards against the client
+	// having already been unregistered (and c.send closed) by readPump.
+	go func() {
+		for _, e := range eventHub.Replay(ownerID, since) {
+			c.deliver(e)
+		}
+	}()
+
+	go readPump(conn, c)
+	writePump(conn, c)
+}
+
+// readPump does nothing with incoming messages beyond keeping the
+// connection's read deadline fresh on pong frames; it exits (and tears
+// down the client) as soon as the connection is closed or goes quiet.
+func readPump(conn *websocket.Conn, c *client) {
+	defer func() {
+		eventHub.Unregister(c)
+		conn.Close()
+	}()
+
+	conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	conn.SetPongHandler(func(string) error {
+		conn.SetReadDeadline(time.Now().Add(wsPongWait))
+		return nil
+	})
+
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+// writePump delivers published events to the client and pings it
+// periodically so dead connections are evicted within wsPongWait.
+func writePump(conn *websocket.Conn, c *client) {
+	ticker := time.NewTicker(wsPingPeriod)
+	defer func() {
+		ticker.Stop()
+		conn.Close()
+	}()
+
+	for {
+		select {
+		case event, ok := <-c.send:
+			if !ok {
+				conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := conn.WriteJSON(event); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}